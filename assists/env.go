@@ -17,10 +17,46 @@ package assists
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
+// DEBUG is the bool value of the env DEBUG, which is kept for compatibility.
+//
+// If the trace categories are not configured by the env DEBUG
+// or SetTraceCategories, setting it to true enables all of them.
 var DEBUG bool
 
 func init() {
-	DEBUG, _ = strconv.ParseBool(os.Getenv("DEBUG"))
+	env := os.Getenv("DEBUG")
+	DEBUG, _ = strconv.ParseBool(env)
+	initTraceCategories(env)
+}
+
+// initTraceCategories configures the trace categories
+// by the value of the env DEBUG only if it is not empty.
+func initTraceCategories(env string) {
+	if strings.TrimSpace(env) != "" {
+		SetTraceCategories(parseTraceCategories(env))
+	}
+}
+
+// parseTraceCategories parses the trace categories, which may be a bool
+// like "true" or "1" to enable all, or a comma-separated list of category
+// names, such as "set,hooks".
+func parseTraceCategories(s string) (c TraceCategory) {
+	if s = strings.TrimSpace(s); s == "" {
+		return
+	}
+
+	if enabled, err := strconv.ParseBool(s); err == nil {
+		if enabled {
+			c = TraceAll
+		}
+		return
+	}
+
+	for _, name := range strings.Split(s, ",") {
+		c |= tracenames[strings.ToLower(strings.TrimSpace(name))]
+	}
+	return
 }
@@ -0,0 +1,151 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+// ExitHookReport is the execution report of an exit function.
+type ExitHookReport struct {
+	Name  string // The name of the hook.
+	Stage string // "exit" for OnExit, "exitpost" for OnExitPost, or "flush" for OnExitFlush.
+	Group string // The group of the hook.
+
+	// The registration site of the exit function.
+	File string
+	Line int
+
+	Start    time.Time
+	Duration time.Duration
+
+	// Err is the error returned by the flusher.
+	Err error
+
+	// Panic and Stacks are the panic value and the stacks where it occurs
+	// if the exit function panics.
+	Panic  any
+	Stacks []runtimex.Frame
+
+	TimedOut bool // Whether the exit function exceeded its timeout.
+	Skipped  bool // Whether the exit function was skipped by ExitTimeout.
+}
+
+// ExitReport is the execution report of RunExit.
+type ExitReport struct {
+	Start    time.Time
+	Duration time.Duration
+
+	// Hooks is the reports of all the exit functions sorted by Start.
+	Hooks []ExitHookReport
+}
+
+func newExitHookReport(h *hook) ExitHookReport {
+	return ExitHookReport{
+		Name:  h.Name,
+		Stage: h.kind,
+		Group: h.Group,
+		File:  h.frame.File,
+		Line:  h.frame.Line,
+	}
+}
+
+type exitrecorder struct {
+	lock    sync.Mutex
+	report  ExitReport
+	running map[*hook]ExitHookReport
+}
+
+func newExitRecorder() *exitrecorder {
+	return &exitrecorder{
+		report:  ExitReport{Start: time.Now()},
+		running: make(map[*hook]ExitHookReport, 4),
+	}
+}
+
+func (r *exitrecorder) begin(h *hook) {
+	report := newExitHookReport(h)
+	report.Start = time.Now()
+
+	r.lock.Lock()
+	r.running[h] = report
+	r.lock.Unlock()
+}
+
+func (r *exitrecorder) add(h *hook, report ExitHookReport) {
+	r.lock.Lock()
+	delete(r.running, h)
+	r.report.Hooks = append(r.report.Hooks, report)
+	r.lock.Unlock()
+}
+
+func (r *exitrecorder) runninghooks() []ExitHookReport {
+	r.lock.Lock()
+	hooks := make([]ExitHookReport, 0, len(r.running))
+	for _, report := range r.running {
+		report.Duration = time.Since(report.Start)
+		hooks = append(hooks, report)
+	}
+	r.lock.Unlock()
+
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].Start.Before(hooks[j].Start) })
+	return hooks
+}
+
+func (r *exitrecorder) finish() *ExitReport {
+	report := r.report
+	report.Duration = time.Since(report.Start)
+	sort.SliceStable(report.Hooks, func(i, j int) bool {
+		return report.Hooks[i].Start.Before(report.Hooks[j].Start)
+	})
+
+	logExitReport(&report)
+	return &report
+}
+
+// logExitReport emits the report as the slog records, which logs
+// each exit function with DEBUG, or WARN if it panicked, timed out
+// or was skipped, and logs the summary with INFO.
+func logExitReport(report *ExitReport) {
+	var slowest ExitHookReport
+	var failures int
+	for _, h := range report.Hooks {
+		level := slog.LevelDebug
+		if h.Err != nil || h.Panic != nil || h.TimedOut || h.Skipped {
+			level = slog.LevelWarn
+			failures++
+		}
+
+		logger(context.Background()).Log(context.Background(), level, "exit function report",
+			"name", h.Name, "stage", h.Stage, "group", h.Group,
+			"file", h.File, "line", h.Line, "duration", h.Duration.String(),
+			"err", h.Err, "panicked", h.Panic != nil, "timedout", h.TimedOut, "skipped", h.Skipped)
+
+		if h.Duration > slowest.Duration {
+			slowest = h
+		}
+	}
+
+	logger(context.Background()).Info("exit report", "duration", report.Duration.String(),
+		"hooks", len(report.Hooks), "failures", failures,
+		"slowest_name", slowest.Name, "slowest_file", slowest.File,
+		"slowest_line", slowest.Line, "slowest_duration", slowest.Duration.String())
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExitReportSummary(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	log := slog.New(slog.NewTextHandler(buf, nil))
+	SetLoggerFunc(func(context.Context) *slog.Logger { return log })
+	t.Cleanup(func() { SetLoggerFunc(nil) })
+
+	l := NewLifecycle()
+	l.Register(StageExit, Hook{Name: "fast", Group: "g1"}, func(context.Context) error { return nil })
+	l.Register(StageExit, Hook{Name: "slow"}, func(context.Context) error {
+		time.Sleep(time.Millisecond * 20)
+		return nil
+	})
+	l.OnExitFlush(testFlusher(func() error { return errors.New("flush") }))
+	l.RunExit()
+
+	report := l.ExitReport()
+	if len(report.Hooks) != 3 {
+		t.Fatalf("expect %d hook reports, but got %d", 3, len(report.Hooks))
+	}
+
+	for _, h := range report.Hooks {
+		if !strings.HasSuffix(h.File, "exitreport_test.go") || h.Line == 0 {
+			t.Errorf("%s: unexpected registration site %s:%d", h.Name, h.File, h.Line)
+		}
+
+		switch h.Name {
+		case "fast":
+			if h.Stage != "exit" || h.Group != "g1" {
+				t.Errorf("fast: unexpected stage '%s' or group '%s'", h.Stage, h.Group)
+			}
+		case "slow":
+			if h.Duration < time.Millisecond*20 {
+				t.Errorf("slow: expect the duration at least 20ms, but got %s", h.Duration)
+			}
+		case "":
+			if h.Stage != "flush" || h.Err == nil {
+				t.Errorf("flush: unexpected stage '%s' or error '%v'", h.Stage, h.Err)
+			}
+		}
+	}
+
+	if report.Duration < time.Millisecond*20 {
+		t.Errorf("expect the total duration at least 20ms, but got %s", report.Duration)
+	}
+
+	logs := buf.String()
+	for _, s := range []string{"msg=\"exit report\"", "hooks=3", "failures=1", "slowest_name=slow"} {
+		if !strings.Contains(logs, s) {
+			t.Errorf("expect '%s' in the exit report log, but got '%s'", s, logs)
+		}
+	}
+}
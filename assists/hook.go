@@ -0,0 +1,313 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+// Hook is the metadata of an init or exit hook, which is used to sort
+// the hooks registered in the same phase.
+//
+// The hooks are sorted topologically by After and Before,
+// and the hooks without the dependency between them are sorted by Priority.
+type Hook struct {
+	// Name is the name of the hook, which may be referred to
+	// by After and Before of other hooks.
+	//
+	// It may be empty, and multiple hooks may have the same name.
+	Name string
+
+	// Priority is the priority of the hook, and the hook with
+	// the smaller priority runs earlier.
+	//
+	// For the hooks with the same priority, the init hooks run
+	// in the registration order, and the exit hooks run in the reverse
+	// registration order.
+	Priority int
+
+	// After is the names of the hooks that this hook must run after.
+	//
+	// The names which do not refer to any hook are ignored.
+	After []string
+
+	// Before is the names of the hooks that this hook must run before.
+	//
+	// The names which do not refer to any hook are ignored.
+	Before []string
+
+	// Timeout is the timeout to call the exit function,
+	// which is ignored by the init function.
+	//
+	// If 0, use ExitHookTimeout instead.
+	Timeout time.Duration
+
+	// Group is the name of the group of the exit function,
+	// which is ignored by the init function.
+	//
+	// The exit functions in the same stage, such as OnExit or OnExitPost,
+	// are partitioned by the group. The groups run concurrently,
+	// and the functions in the same group run in turn. So the dependency
+	// by After and Before only takes effect in the same group.
+	//
+	// All the exit functions without the group are in the default group "".
+	Group string
+}
+
+type hook struct {
+	Hook
+	kind  string
+	seq   int64
+	frame runtimex.Frame
+	run   func(context.Context) error
+}
+
+func (h *hook) String() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.frame.String()
+}
+
+// Handle is the handle of a registered init or exit function.
+type Handle struct {
+	hooks *hooks
+	hook  *hook
+}
+
+// Cancel removes the registered function, which is safe to be called
+// concurrently with the registration, and more than once.
+//
+// It has no effect on the running RunInit or RunExit which has started
+// to call the functions.
+func (h Handle) Cancel() {
+	if h.hooks != nil {
+		h.hooks.remove(h.hook)
+	}
+}
+
+// Registered reports whether the function is still registered,
+// that is, it has been neither cancelled nor reset.
+func (h Handle) Registered() bool {
+	if h.hooks == nil {
+		return false
+	}
+
+	h.hooks.lock.Lock()
+	defer h.hooks.lock.Unlock()
+	for _, _h := range h.hooks.list {
+		if _h == h.hook {
+			return true
+		}
+	}
+	return false
+}
+
+// Caller returns the caller which registers the function.
+func (h Handle) Caller() (frame runtimex.Frame) {
+	if h.hook != nil {
+		frame = h.hook.frame
+	}
+	return
+}
+
+type hooks struct {
+	lock sync.Mutex
+	list []*hook
+}
+
+var hookseq atomic.Int64
+
+// hookfunc converts the function f registered by Lifecycle.register,
+// and returns nil if f is nil.
+func hookfunc(f any) func(context.Context) error {
+	switch f := f.(type) {
+	case func(context.Context) error:
+		return f
+
+	case func():
+		if f != nil {
+			return func(context.Context) error { f(); return nil }
+		}
+
+	case func(context.Context):
+		if f != nil {
+			return func(ctx context.Context) error { f(ctx); return nil }
+		}
+
+	case Flusher:
+		return func(context.Context) error { return f.Flush() }
+	}
+	return nil
+}
+
+func (hs *hooks) add(kind string, frame runtimex.Frame, h Hook, f func(context.Context) error) Handle {
+	if f == nil {
+		panic(fmt.Errorf("%s function must not be nil", kind))
+	}
+
+	_hook := &hook{Hook: h, kind: kind, seq: hookseq.Add(1), frame: frame, run: f}
+
+	hs.lock.Lock()
+	hs.list = append(hs.list, _hook)
+	hs.lock.Unlock()
+
+	_traceregister(kind, frame)
+	return Handle{hooks: hs, hook: _hook}
+}
+
+// call calls the hook function and traces it with TraceHookRun.
+func (h *hook) call(ctx context.Context) (err error) {
+	if !Tracing(TraceHookRun) {
+		return h.run(ctx)
+	}
+
+	start := time.Now()
+	err = h.run(ctx)
+
+	msg := fmt.Sprintf("run %s function", h.kind)
+	trace(TraceHookRun, h.frame, msg, []slog.Attr{slog.String("name", h.Name),
+		slog.String("duration", time.Since(start).String()), slog.Any("err", err)})
+	return
+}
+
+func (hs *hooks) remove(h *hook) {
+	hs.lock.Lock()
+	defer hs.lock.Unlock()
+	for i, _h := range hs.list {
+		if _h == h {
+			hs.list = append(hs.list[:i:i], hs.list[i+1:]...)
+			return
+		}
+	}
+}
+
+func (hs *hooks) reset() {
+	hs.lock.Lock()
+	hs.list = nil
+	hs.lock.Unlock()
+}
+
+func (hs *hooks) snapshot() []*hook {
+	hs.lock.Lock()
+	defer hs.lock.Unlock()
+	return hs.list
+}
+
+// sort returns the hooks sorted topologically by After and Before,
+// then by Priority and the registration order, which is reversed
+// if reverse is true.
+//
+// If there is a dependency cycle, it returns an error, and the hooks
+// in the cycle are appended in the order of Priority and registration.
+func (hs *hooks) sort(reverse bool) ([]*hook, error) {
+	return sorthooks(hs.snapshot(), reverse)
+}
+
+func sorthooks(hs []*hook, reverse bool) ([]*hook, error) {
+	_len := len(hs)
+	indexes := make(map[string][]int, _len)
+	for i, h := range hs {
+		if h.Name != "" {
+			indexes[h.Name] = append(indexes[h.Name], i)
+		}
+	}
+
+	// edges[i] contains the hooks that must run after the hook i.
+	edges := make([][]int, _len)
+	indegrees := make([]int, _len)
+	addedge := func(from, to int) {
+		if from != to {
+			edges[from] = append(edges[from], to)
+			indegrees[to]++
+		}
+	}
+
+	for i, h := range hs {
+		for _, name := range h.After {
+			for _, j := range indexes[name] {
+				addedge(j, i)
+			}
+		}
+		for _, name := range h.Before {
+			for _, j := range indexes[name] {
+				addedge(i, j)
+			}
+		}
+	}
+
+	less := func(i, j int) bool {
+		hi, hj := hs[i], hs[j]
+		switch {
+		case hi.Priority != hj.Priority:
+			return hi.Priority < hj.Priority
+		case reverse:
+			return hi.seq > hj.seq
+		default:
+			return hi.seq < hj.seq
+		}
+	}
+
+	// next returns the index of the first hook by less among the remaining
+	// ones, which only contains the hooks without any dependency if ready.
+	done := make([]bool, _len)
+	next := func(ready bool) (index int) {
+		index = -1
+		for i := 0; i < _len; i++ {
+			if done[i] || (ready && indegrees[i] > 0) {
+				continue
+			}
+			if index < 0 || less(i, index) {
+				index = i
+			}
+		}
+		return
+	}
+
+	sorted := make([]*hook, 0, _len)
+	for len(sorted) < _len {
+		i := next(true)
+		if i < 0 {
+			break
+		}
+
+		done[i] = true
+		sorted = append(sorted, hs[i])
+		for _, j := range edges[i] {
+			indegrees[j]--
+		}
+	}
+
+	if len(sorted) == _len {
+		return sorted, nil
+	}
+
+	cycles := make([]string, 0, _len-len(sorted))
+	for i := next(false); i >= 0; i = next(false) {
+		done[i] = true
+		sorted = append(sorted, hs[i])
+		cycles = append(cycles, hs[i].String())
+	}
+
+	return sorted, fmt.Errorf("found a dependency cycle in the hooks [%s]", strings.Join(cycles, ", "))
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+func testhooks(hs ...Hook) *hooks {
+	list := new(hooks)
+	for _, h := range hs {
+		list.add("test", runtimex.Caller(0), h, hookfunc(func() {}))
+	}
+	return list
+}
+
+func hooknames(hs []*hook) string {
+	names := make([]string, len(hs))
+	for i, h := range hs {
+		names[i] = h.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestHooksSort(t *testing.T) {
+	hs := testhooks(
+		Hook{Name: "a"},
+		Hook{Name: "b", Priority: -1},
+		Hook{Name: "c", After: []string{"d"}},
+		Hook{Name: "d", Priority: 1},
+		Hook{Name: "e", Before: []string{"b"}, After: []string{"unknown"}},
+	)
+
+	if sorted, err := hs.sort(false); err != nil {
+		t.Error(err)
+	} else if names := hooknames(sorted); names != "a,e,b,d,c" {
+		t.Errorf("expect '%s', but got '%s'", "a,e,b,d,c", names)
+	}
+
+	if sorted, err := hs.sort(true); err != nil {
+		t.Error(err)
+	} else if names := hooknames(sorted); names != "e,b,a,d,c" {
+		t.Errorf("expect '%s', but got '%s'", "e,b,a,d,c", names)
+	}
+
+	hs = testhooks(Hook{Name: "a"}, Hook{Name: "b"}, Hook{Name: "c"})
+	if sorted, _ := hs.sort(true); hooknames(sorted) != "c,b,a" {
+		t.Errorf("expect '%s', but got '%s'", "c,b,a", hooknames(sorted))
+	}
+
+	hs = testhooks(
+		Hook{Name: "a", After: []string{"c"}},
+		Hook{Name: "b", After: []string{"a"}},
+		Hook{Name: "c", After: []string{"b"}},
+		Hook{Name: "d"},
+	)
+	sorted, err := hs.sort(false)
+	if err == nil {
+		t.Errorf("expect a dependency cycle error, but got nil")
+	} else if !strings.Contains(err.Error(), "[a, b, c]") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if names := hooknames(sorted); names != "d,a,b,c" {
+		t.Errorf("expect '%s', but got '%s'", "d,a,b,c", names)
+	}
+}
+
+func TestGroupExits(t *testing.T) {
+	hs := testhooks(
+		Hook{Name: "a", Group: "g1"},
+		Hook{Name: "b"},
+		Hook{Name: "c", Group: "g2"},
+		Hook{Name: "d", Group: "g1"},
+		Hook{Name: "e"},
+	)
+
+	groups := groupexits(hs.snapshot())
+	if len(groups) != 3 {
+		t.Fatalf("expect %d groups, but got %d", 3, len(groups))
+	}
+
+	expects := []string{"a,d", "b,e", "c"}
+	for i, group := range groups {
+		if names := hooknames(group); names != expects[i] {
+			t.Errorf("group %d: expect '%s', but got '%s'", i, expects[i], names)
+		}
+	}
+}
+
+func TestHandleCancel(t *testing.T) {
+	hs := testhooks(Hook{Name: "a"}, Hook{Name: "b"})
+	handle := hs.add("test", runtimex.Caller(0), Hook{Name: "c"}, hookfunc(func() {}))
+	hs.add("test", runtimex.Caller(0), Hook{Name: "d"}, hookfunc(func() {}))
+
+	handle.Cancel()
+	handle.Cancel()
+	if names := hooknames(hs.snapshot()); names != "a,b,d" {
+		t.Errorf("expect '%s', but got '%s'", "a,b,d", names)
+	}
+}
@@ -17,100 +17,169 @@ package assists
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/xgfone/go-defaults/internal/lifecycle"
 	"github.com/xgfone/go-toolkit/runtimex"
 )
 
+func init() {
+	lifecycle.Register = func(skip int, stage Stage, h Hook, f any) Handle {
+		return DefaultLifecycle.register(skip+1, stage, h, f)
+	}
+}
+
 /// ----------------------------------------------------------------------- ///
 
-var (
-	init0funcs []func()
-	init1funcs []func()
-)
+// Register registers the function f called in the stage sorted by the hook h,
+// which is the proxy of DefaultLifecycle.Register.
+func Register(stage Stage, h Hook, f func(context.Context) error) Handle {
+	return DefaultLifecycle.register(1, stage, h, f)
+}
 
 // OnInitPre registers a pre-init function called before calling init functions
 // when calling RunInit().
-func OnInitPre(f func()) {
-	init0funcs = append(init0funcs, f)
-	_traceregister("init0")
-}
+func OnInitPre(f func()) Handle { return DefaultLifecycle.register(1, StageInitPre, Hook{}, f) }
 
 // OnInit registers an init function called when calling RunInit().
-func OnInit(f func()) {
-	init1funcs = append(init1funcs, f)
-	_traceregister("init1")
+func OnInit(f func()) Handle { return DefaultLifecycle.register(1, StageInit, Hook{}, f) }
+
+// OnInitPreE is the same as OnInitPre, but the function may return an error
+// to abort RunInitContext.
+func OnInitPreE(f func(context.Context) error) Handle {
+	return DefaultLifecycle.register(1, StageInitPre, Hook{}, f)
 }
 
-// RunInit calls the init functions in turn.
-func RunInit() {
-	iter(init0funcs, func(f func()) { f() })
-	iter(init1funcs, func(f func()) { f() })
+// OnInitE is the same as OnInit, but the function may return an error
+// to abort RunInitContext.
+func OnInitE(f func(context.Context) error) Handle {
+	return DefaultLifecycle.register(1, StageInit, Hook{}, f)
 }
 
+// RunInit is equal to RunInitContext(context.Background()),
+// but panics if it returns an error.
+func RunInit() { DefaultLifecycle.RunInit() }
+
+// RunInitContext calls the pre-init and init functions in turn,
+// which is the proxy of DefaultLifecycle.RunInitContext.
+func RunInitContext(ctx context.Context) error { return DefaultLifecycle.RunInitContext(ctx) }
+
 /// ----------------------------------------------------------------------- ///
 
 var (
-	exitfuncs  []func()
-	cleanfuncs []func()
-	exitonce   sync.Once
-	exitedch   = make(chan struct{})
-
-	exitctx, exitcancel = context.WithCancel(context.Background())
+	exittimeout     atomic.Int64
+	exithooktimeout atomic.Int64
+	exitdraindelay  atomic.Int64
+	exitflushdelay  atomic.Int64
 )
 
-// OnExitPost registers a function called after calling exit functions.
-func OnExitPost(f func()) {
-	cleanfuncs = append(cleanfuncs, f)
-	_traceregister("exitpost")
-}
+// ExitTimeout returns the overall deadline to call all the exit
+// and post-exit functions when calling RunExit.
+//
+// When the deadline is exceeded, the rest exit functions are skipped.
+//
+// Default: 0, which means no deadline.
+func ExitTimeout() time.Duration { return time.Duration(exittimeout.Load()) }
 
-// OnExit registers a function called when calling RunExit().
-func OnExit(f func()) {
-	exitfuncs = append(exitfuncs, f)
-	_traceregister("exit")
-}
+// SetExitTimeout resets the overall deadline of RunExit. See ExitTimeout.
+func SetExitTimeout(timeout time.Duration) { exittimeout.Store(int64(timeout)) }
 
-// ExitContext returns a context that it will be cancelled when calling RunExit.
-func ExitContext() context.Context { return exitctx }
+// ExitHookTimeout returns the default timeout to call each exit
+// or post-exit function, which may be overridden by Hook.Timeout.
+//
+// When the timeout is exceeded, the exit function is abandoned
+// and RunExit moves on to the next one.
+//
+// Default: 0, which means no timeout.
+func ExitHookTimeout() time.Duration { return time.Duration(exithooktimeout.Load()) }
 
-// WaitExit waits until all exit functions finish to be called.
-func WaitExit() { <-exitedch }
+// SetExitHookTimeout resets the default timeout of each exit function.
+// See ExitHookTimeout.
+func SetExitHookTimeout(timeout time.Duration) { exithooktimeout.Store(int64(timeout)) }
+
+// ExitDrainDelay returns the delay to wait in the state StateDraining,
+// during which the readiness fails, before cancelling the exit context
+// and calling the exit functions when calling RunExit, so that
+// the load balancers have time to remove the program.
+//
+// Default: 0, which means no delay.
+func ExitDrainDelay() time.Duration { return time.Duration(exitdraindelay.Load()) }
+
+// SetExitDrainDelay resets the delay in the state StateDraining.
+// See ExitDrainDelay.
+func SetExitDrainDelay(delay time.Duration) { exitdraindelay.Store(int64(delay)) }
+
+// ExitFlushDelay returns the delay to wait for the final flush
+// after calling all the exit functions and flushers.
+//
+// Default: 0, which means no delay. It may be set for the asynchronous
+// writers which cannot be flushed by the flushers of OnExitFlush.
+func ExitFlushDelay() time.Duration { return time.Duration(exitflushdelay.Load()) }
+
+// SetExitFlushDelay resets the delay for the final flush. See ExitFlushDelay.
+func SetExitFlushDelay(delay time.Duration) { exitflushdelay.Store(int64(delay)) }
 
-// RunExit calls the exit functions in reverse turn.
-func RunExit() {
-	exitonce.Do(exit)
-	WaitExit()
+// Flusher is used to flush the buffered data, such as log buffers,
+// when the program exits.
+type Flusher interface {
+	Flush() error
 }
 
-func exit() {
-	exitcancel()
-	reverseIter(exitfuncs, runexit)
-	reverseIter(cleanfuncs, runexit)
-	close(exitedch)
+// OnExitPost registers a function called after calling exit functions.
+func OnExitPost(f func()) Handle { return DefaultLifecycle.register(1, StageExitPost, Hook{}, f) }
+
+// OnExit registers a function called when calling RunExit().
+func OnExit(f func()) Handle { return DefaultLifecycle.register(1, StageExit, Hook{}, f) }
+
+// OnExitPostCtx is the same as OnExitPost, but the function receives
+// a context which is done when the timeout of the function is exceeded.
+func OnExitPostCtx(f func(context.Context)) Handle {
+	return DefaultLifecycle.register(1, StageExitPost, Hook{}, f)
 }
 
-func runexit(f func()) {
-	defer exitrecover()
-	f()
+// OnExitCtx is the same as OnExit, but the function receives
+// a context which is done when the timeout of the function is exceeded.
+func OnExitCtx(f func(context.Context)) Handle {
+	return DefaultLifecycle.register(1, StageExit, Hook{}, f)
 }
 
-func exitrecover() {
-	if r := recover(); r != nil {
-		slog.Error("exit func panics", "panic", r)
-	}
+// OnExitFlush registers a flusher called after calling the post-exit functions,
+// which is awaited explicitly and whose error is logged.
+//
+// The flushers run in reverse turn, and also respect ExitTimeout
+// and ExitHookTimeout.
+func OnExitFlush(f Flusher) Handle {
+	return DefaultLifecycle.register(1, StageExitFlush, Hook{}, f)
 }
 
-func init() { OnExitPost(func() { time.Sleep(time.Millisecond * 10) }) }
+// ExitContext returns a context that it will be cancelled when calling RunExit.
+func ExitContext() context.Context { return DefaultLifecycle.ExitContext() }
+
+// WaitExit waits until all exit functions finish to be called.
+func WaitExit() { DefaultLifecycle.WaitExit() }
+
+// RunExit calls the exit functions in reverse turn,
+// which is the proxy of DefaultLifecycle.RunExit.
+func RunExit() { DefaultLifecycle.RunExit() }
+
+// RunningExitHooks returns the reports of the exit functions which are
+// being called by RunExit, which is the proxy of DefaultLifecycle.RunningExitHooks.
+func RunningExitHooks() []ExitHookReport { return DefaultLifecycle.RunningExitHooks() }
+
+// GetState returns the current state of the default lifecycle,
+// which is the proxy of DefaultLifecycle.State.
+func GetState() State { return DefaultLifecycle.State() }
+
+// GetExitReport returns the execution report of RunExit,
+// which returns nil before RunExit finishes.
+func GetExitReport() *ExitReport { return DefaultLifecycle.ExitReport() }
 
 /// ----------------------------------------------------------------------- ///
 
-func _traceregister(kind string) {
-	if DEBUG {
-		frame := runtimex.Caller(2)
+func _traceregister(kind string, frame runtimex.Frame) {
+	if Tracing(TraceHookRegister) {
 		msg := fmt.Sprintf("register %s function", kind)
-		slog.Info(msg, "file", frame.File, "line", frame.Line)
+		trace(TraceHookRegister, frame, msg, nil)
 	}
 }
@@ -0,0 +1,402 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+// DefaultLifecycle is the default lifecycle, which backs the package-level
+// functions, such as OnInit, RunInit, OnExit, RunExit, etc.
+var DefaultLifecycle = NewLifecycle()
+
+// Lifecycle manages the init and exit functions of the program.
+//
+// The configuration, such as ExitTimeout, ExitHookTimeout
+// and ExitFlushDelay, is shared by all the lifecycles.
+type Lifecycle struct {
+	stages [stagenum]hooks
+
+	exitonce   sync.Once
+	exitedch   chan struct{}
+	exitctx    context.Context
+	exitcancel context.CancelFunc
+	exitreport atomic.Pointer[ExitReport]
+	exitrecord atomic.Pointer[exitrecorder]
+	state      atomic.Int32
+}
+
+// NewLifecycle returns a new isolated lifecycle.
+func NewLifecycle() *Lifecycle {
+	l := new(Lifecycle)
+	l.reset()
+	return l
+}
+
+// Reset clears all the registered init and exit functions, and resets
+// the exit state, so that the lifecycle can be run again, which is
+// mainly used by the tests, for example,
+//
+//	assists.DefaultLifecycle.Reset()
+//
+// NOTICE: It must not be called concurrently with other methods.
+func (l *Lifecycle) Reset() { l.reset() }
+
+func (l *Lifecycle) reset() {
+	for i := range l.stages {
+		l.stages[i].reset()
+	}
+
+	l.exitonce = sync.Once{}
+	l.exitedch = make(chan struct{})
+	l.exitctx, l.exitcancel = context.WithCancel(context.Background())
+	l.exitreport.Store(nil)
+	l.exitrecord.Store(nil)
+	l.state.Store(int32(StateStarting))
+}
+
+// State returns the current state of the lifecycle.
+func (l *Lifecycle) State() State { return State(l.state.Load()) }
+
+// Stage is the stage of the lifecycle in which the registered functions run.
+type Stage uint8
+
+// Pre-define the stages of the lifecycle.
+const (
+	StageInitPre   Stage = iota // The pre-init functions called by RunInit.
+	StageInit                   // The init functions called by RunInit.
+	StageExit                   // The exit functions called by RunExit.
+	StageExitPost               // The post-exit functions called by RunExit.
+	StageExitFlush              // The flushers called by RunExit.
+
+	stagenum = iota
+)
+
+var stagenames = [stagenum]string{"init0", "init1", "exit", "exitpost", "flush"}
+
+// String returns the name of the stage.
+func (s Stage) String() string {
+	if s < stagenum {
+		return stagenames[s]
+	}
+	return fmt.Sprintf("Stage(%d)", s)
+}
+
+// register registers the function f, which is one of func(),
+// func(context.Context), func(context.Context) error and Flusher,
+// and records the caller of the function calling register
+// after skipping the extra skip stack frames.
+func (l *Lifecycle) register(skip int, stage Stage, h Hook, f any) Handle {
+	if stage >= stagenum {
+		panic(fmt.Errorf("unknown lifecycle stage %d", stage))
+	}
+	return l.stages[stage].add(stage.String(), runtimex.Caller(skip+1), h, hookfunc(f))
+}
+
+/// ----------------------------------------------------------------------- ///
+
+// Register registers the function f called in the stage,
+// which is sorted by the hook h.
+func (l *Lifecycle) Register(stage Stage, h Hook, f func(context.Context) error) Handle {
+	return l.register(1, stage, h, f)
+}
+
+// OnInitPre registers a pre-init function called before calling init functions
+// when calling RunInit().
+func (l *Lifecycle) OnInitPre(f func()) Handle { return l.register(1, StageInitPre, Hook{}, f) }
+
+// OnInit registers an init function called when calling RunInit().
+func (l *Lifecycle) OnInit(f func()) Handle { return l.register(1, StageInit, Hook{}, f) }
+
+// OnInitPreE is the same as OnInitPre, but the function may return an error
+// to abort RunInitContext.
+func (l *Lifecycle) OnInitPreE(f func(context.Context) error) Handle {
+	return l.register(1, StageInitPre, Hook{}, f)
+}
+
+// OnInitE is the same as OnInit, but the function may return an error
+// to abort RunInitContext.
+func (l *Lifecycle) OnInitE(f func(context.Context) error) Handle {
+	return l.register(1, StageInit, Hook{}, f)
+}
+
+// RunInit is equal to RunInitContext(context.Background()),
+// but panics if it returns an error.
+func (l *Lifecycle) RunInit() {
+	if err := l.RunInitContext(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+// RunInitContext calls the pre-init and init functions in turn,
+// which are sorted by their hooks.
+//
+// If there is a dependency cycle in the hooks, it returns an error
+// without calling any init function.
+//
+// If an init function fails, it logs the failed function with its
+// registration site, stops calling the rest init functions, calls RunExit
+// to run the exit functions which have been registered for the partially
+// initialized components, and returns the error.
+//
+// If all the init functions succeed, the state changes from StateStarting
+// to StateReady.
+func (l *Lifecycle) RunInitContext(ctx context.Context) error {
+	init0, err := l.stages[StageInitPre].sort(false)
+	if err != nil {
+		return err
+	}
+
+	init1, err := l.stages[StageInit].sort(false)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range append(init0, init1...) {
+		if err := h.call(ctx); err != nil {
+			logger(ctx).Error("fail to run the init function", "name", h.Name,
+				"file", h.frame.File, "line", h.frame.Line, "err", err)
+
+			l.RunExit()
+			return fmt.Errorf("init function %s: %w", h, err)
+		}
+	}
+
+	l.state.CompareAndSwap(int32(StateStarting), int32(StateReady))
+	return nil
+}
+
+/// ----------------------------------------------------------------------- ///
+
+// OnExitPost registers a function called after calling exit functions.
+func (l *Lifecycle) OnExitPost(f func()) Handle { return l.register(1, StageExitPost, Hook{}, f) }
+
+// OnExit registers a function called when calling RunExit().
+func (l *Lifecycle) OnExit(f func()) Handle { return l.register(1, StageExit, Hook{}, f) }
+
+// OnExitPostCtx is the same as OnExitPost, but the function receives
+// a context which is done when the timeout of the function is exceeded.
+func (l *Lifecycle) OnExitPostCtx(f func(context.Context)) Handle {
+	return l.register(1, StageExitPost, Hook{}, f)
+}
+
+// OnExitCtx is the same as OnExit, but the function receives
+// a context which is done when the timeout of the function is exceeded.
+func (l *Lifecycle) OnExitCtx(f func(context.Context)) Handle {
+	return l.register(1, StageExit, Hook{}, f)
+}
+
+// OnExitFlush registers a flusher called after calling the post-exit functions,
+// which is awaited explicitly and whose error is logged.
+//
+// The flushers run in reverse turn, and also respect ExitTimeout
+// and ExitHookTimeout.
+func (l *Lifecycle) OnExitFlush(f Flusher) Handle {
+	return l.register(1, StageExitFlush, Hook{}, f)
+}
+
+// ExitContext returns a context that it will be cancelled when calling RunExit.
+func (l *Lifecycle) ExitContext() context.Context { return l.exitctx }
+
+// WaitExit waits until all exit functions finish to be called.
+func (l *Lifecycle) WaitExit() { <-l.exitedch }
+
+// ExitReport returns the execution report of RunExit,
+// which returns nil before RunExit finishes.
+func (l *Lifecycle) ExitReport() *ExitReport { return l.exitreport.Load() }
+
+// RunningExitHooks returns the reports of the exit functions which are
+// being called by RunExit, whose Duration is the elapsed time so far.
+//
+// It returns nil if RunExit has not started.
+func (l *Lifecycle) RunningExitHooks() []ExitHookReport {
+	if recorder := l.exitrecord.Load(); recorder != nil {
+		return recorder.runninghooks()
+	}
+	return nil
+}
+
+// RunExit calls the exit functions in reverse turn,
+// which are sorted by their hooks.
+//
+// The exit functions registered by OnExit and OnExitPost, and the flushers
+// registered by OnExitFlush, run in the stages StageExit, StageExitPost
+// and StageExitFlush in turn, then wait for ExitFlushDelay if it is
+// positive. The groups of the exit functions in the same stage run
+// concurrently. See Hook.Group.
+//
+// If there is a dependency cycle in the hooks, it logs the error
+// and still calls all the exit functions.
+//
+// When starting, the state changes to StateDraining, then it waits for
+// ExitDrainDelay if it is positive before cancelling the exit context
+// and calling the exit functions. When finishing, the state changes
+// to StateStopped.
+//
+// See ExitTimeout and ExitHookTimeout about the timeout.
+func (l *Lifecycle) RunExit() {
+	l.exitonce.Do(l.exit)
+	l.WaitExit()
+}
+
+func (l *Lifecycle) exit() {
+	l.state.Store(int32(StateDraining))
+	if delay := ExitDrainDelay(); delay > 0 {
+		time.Sleep(delay)
+	}
+	l.exitcancel()
+
+	ctx := context.Background()
+	if timeout := ExitTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	recorder := newExitRecorder()
+	l.exitrecord.Store(recorder)
+	for stage := StageExit; stage < stagenum; stage++ {
+		runstage(ctx, recorder, sortexit(&l.stages[stage]))
+	}
+	l.exitreport.Store(recorder.finish())
+
+	if delay := ExitFlushDelay(); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	l.state.Store(int32(StateStopped))
+	close(l.exitedch)
+}
+
+func runstage(ctx context.Context, recorder *exitrecorder, hs []*hook) {
+	groups := groupexits(hs)
+	if len(groups) == 1 {
+		runexits(ctx, recorder, groups[0])
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(groups))
+	for _, group := range groups {
+		go func(hs []*hook) {
+			defer wg.Done()
+			runexits(ctx, recorder, hs)
+		}(group)
+	}
+	wg.Wait()
+}
+
+// groupexits partitions the sorted hooks by the group,
+// and keeps the order of the hooks in the same group.
+func groupexits(hs []*hook) (groups [][]*hook) {
+	indexes := make(map[string]int, 4)
+	for _, h := range hs {
+		index, ok := indexes[h.Group]
+		if !ok {
+			index = len(groups)
+			indexes[h.Group] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], h)
+	}
+	return
+}
+
+func runexits(ctx context.Context, recorder *exitrecorder, hs []*hook) {
+	for _, h := range hs {
+		if ctx.Err() != nil {
+			logger(ctx).Error("skip the exit function as the exit deadline is exceeded",
+				"name", h.Name, "file", h.frame.File, "line", h.frame.Line)
+
+			report := newExitHookReport(h)
+			report.Skipped = true
+			recorder.add(h, report)
+			continue
+		}
+
+		recorder.begin(h)
+		recorder.add(h, runexit(ctx, h))
+	}
+}
+
+func sortexit(hs *hooks) []*hook {
+	sorted, err := hs.sort(true)
+	if err != nil {
+		logger(context.Background()).Error("fail to sort the exit functions", "err", err)
+	}
+	return sorted
+}
+
+func runexit(ctx context.Context, h *hook) (report ExitHookReport) {
+	report = newExitHookReport(h)
+	report.Start = time.Now()
+	defer func() { report.Duration = time.Since(report.Start) }()
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = ExitHookTimeout()
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		callexit(ctx, h, &report)
+		return
+	}
+
+	// Use a separate report in the goroutine to avoid the data race
+	// when the exit function is abandoned.
+	done := make(chan struct{})
+	result := report
+	go func() {
+		defer close(done)
+		callexit(ctx, h, &result)
+	}()
+
+	select {
+	case <-done:
+		report = result
+	case <-ctx.Done():
+		report.TimedOut = true
+		logger(ctx).Error("the exit function exceeded its timeout, and abandon it",
+			"name", h.Name, "file", h.frame.File, "line", h.frame.Line,
+			"elapsed", time.Since(report.Start).String())
+	}
+	return
+}
+
+func callexit(ctx context.Context, h *hook, report *ExitHookReport) {
+	defer exitrecover(h, report)
+	// The error is logged by the exit report.
+	report.Err = h.call(ctx)
+}
+
+func exitrecover(h *hook, report *ExitHookReport) {
+	if r := recover(); r != nil {
+		report.Panic, report.Stacks = r, runtimex.Stacks(2)
+		logger(context.Background()).Error("exit func panics", "name", h.Name, "file", h.frame.File,
+			"line", h.frame.Line, "panic", r, "stacks", report.Stacks)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLifecycleRunInitContext(t *testing.T) {
+	var calls []string
+	l := NewLifecycle()
+	l.OnInit(func() { calls = append(calls, "init1") })
+	l.OnInitE(func(context.Context) error {
+		l.OnExit(func() { calls = append(calls, "exit1") })
+		return nil
+	})
+	l.OnInitE(func(context.Context) error { return errors.New("test") })
+	l.OnInit(func() { calls = append(calls, "init2") })
+	l.OnExitPost(func() { calls = append(calls, "exitpost") })
+
+	err := l.RunInitContext(context.Background())
+	if err == nil || !strings.HasSuffix(err.Error(), ": test") {
+		t.Errorf("expect an init error, but got %v", err)
+	}
+
+	if s := strings.Join(calls, ","); s != "init1,exit1,exitpost" {
+		t.Errorf("expect calls '%s', but got '%s'", "init1,exit1,exitpost", s)
+	}
+
+	if l.ExitContext().Err() == nil {
+		t.Errorf("expect the exit context is done")
+	}
+}
+
+func TestLifecycleExitReport(t *testing.T) {
+	l := NewLifecycle()
+	l.Register(StageExit, Hook{Name: "panic"}, func(context.Context) error { panic("test") })
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	l.Register(StageExit, Hook{Name: "timeout", Timeout: time.Millisecond * 10}, func(context.Context) error {
+		<-stop // Ignore ctx to be abandoned, but exit when the test finishes.
+		return nil
+	})
+	l.RunExit()
+
+	report := l.ExitReport()
+	if report == nil {
+		t.Fatal("expect an exit report, but got nil")
+	} else if len(report.Hooks) != 2 {
+		t.Fatalf("expect %d hook reports, but got %d", 2, len(report.Hooks))
+	}
+
+	if h := report.Hooks[0]; h.Name != "timeout" || !h.TimedOut {
+		t.Errorf("expect the timed-out hook, but got %+v", h)
+	}
+	if h := report.Hooks[1]; h.Name != "panic" || h.Panic != "test" || len(h.Stacks) == 0 {
+		t.Errorf("expect the panicked hook, but got %+v", h)
+	}
+
+	l.Reset()
+	if l.ExitReport() != nil || l.ExitContext().Err() != nil {
+		t.Errorf("expect the lifecycle is reset")
+	}
+}
+
+func TestLifecycleState(t *testing.T) {
+	l := NewLifecycle()
+	if state := l.State(); state != StateStarting {
+		t.Errorf("expect state '%s', but got '%s'", StateStarting, state)
+	}
+
+	l.RunInit()
+	if state := l.State(); state != StateReady {
+		t.Errorf("expect state '%s', but got '%s'", StateReady, state)
+	}
+
+	l.OnExit(func() {
+		if state := l.State(); state != StateDraining {
+			t.Errorf("expect state '%s', but got '%s'", StateDraining, state)
+		}
+	})
+
+	l.RunExit()
+	if state := l.State(); state != StateStopped {
+		t.Errorf("expect state '%s', but got '%s'", StateStopped, state)
+	}
+}
+
+type testFlusher func() error
+
+func (f testFlusher) Flush() error { return f() }
+
+func TestLifecycleExitFlush(t *testing.T) {
+	var calls []string
+	errflush := errors.New("flush")
+
+	l := NewLifecycle()
+	l.OnExitFlush(testFlusher(func() error { calls = append(calls, "flush1"); return errflush }))
+	l.OnExitFlush(testFlusher(func() error { calls = append(calls, "flush2"); return nil }))
+	l.OnExitPost(func() { calls = append(calls, "exitpost") })
+	l.OnExit(func() { calls = append(calls, "exit") })
+	l.RunExit()
+
+	if s := strings.Join(calls, ","); s != "exit,exitpost,flush2,flush1" {
+		t.Errorf("expect calls '%s', but got '%s'", "exit,exitpost,flush2,flush1", s)
+	}
+
+	report := l.ExitReport()
+	if len(report.Hooks) != 4 {
+		t.Fatalf("expect %d hook reports, but got %d", 4, len(report.Hooks))
+	}
+	if h := report.Hooks[3]; h.Stage != "flush" || !errors.Is(h.Err, errflush) {
+		t.Errorf("expect the failed flusher, but got %+v", h)
+	}
+}
+
+func TestLifecycleRunningExitHooks(t *testing.T) {
+	l := NewLifecycle()
+	if hooks := l.RunningExitHooks(); hooks != nil {
+		t.Errorf("expect no running exit hooks before exiting, but got %+v", hooks)
+	}
+
+	var running []ExitHookReport
+	l.Register(StageExit, Hook{Name: "running"}, func(context.Context) error {
+		running = l.RunningExitHooks()
+		return nil
+	})
+	l.RunExit()
+
+	if len(running) != 1 || running[0].Name != "running" {
+		t.Errorf("expect the running exit hook 'running', but got %+v", running)
+	}
+	if hooks := l.RunningExitHooks(); len(hooks) != 0 {
+		t.Errorf("expect no running exit hooks after exiting, but got %+v", hooks)
+	}
+}
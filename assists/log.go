@@ -0,0 +1,54 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+var loggerfunc atomic.Pointer[func(context.Context) *slog.Logger]
+
+// LoggerFunc returns the function to get the logger by the context
+// to log the messages in this package.
+//
+// Default: return slog.Default()
+func LoggerFunc() func(context.Context) *slog.Logger {
+	if f := loggerfunc.Load(); f != nil {
+		return *f
+	}
+	return defaultLogger
+}
+
+// SetLoggerFunc resets the function to get the logger by the context.
+//
+// If f is nil, reset it to the default. See LoggerFunc.
+func SetLoggerFunc(f func(context.Context) *slog.Logger) {
+	if f == nil {
+		loggerfunc.Store(nil)
+	} else {
+		loggerfunc.Store(&f)
+	}
+}
+
+func defaultLogger(context.Context) *slog.Logger { return slog.Default() }
+
+func logger(ctx context.Context) *slog.Logger {
+	if l := LoggerFunc()(ctx); l != nil {
+		return l
+	}
+	return slog.Default()
+}
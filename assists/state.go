@@ -0,0 +1,52 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+// State represents the state of the lifecycle.
+//
+//	StateStarting -> StateReady -> StateDraining -> StateStopped
+type State int32
+
+// Predefine some lifecycle states.
+const (
+	// StateStarting is the initial state before RunInit finishes successfully.
+	StateStarting State = iota
+
+	// StateReady is the state after RunInit finishes successfully.
+	StateReady
+
+	// StateDraining is the state after RunExit starts, during which
+	// the readiness fails and the exit functions are called.
+	StateDraining
+
+	// StateStopped is the state after all the exit functions finish.
+	StateStopped
+)
+
+// String returns the string representation of the state.
+func (s State) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateReady:
+		return "ready"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+// TraceCategory is the bit set of the categories of the trace events.
+type TraceCategory uint8
+
+// Pre-define some trace categories.
+const (
+	TraceValueSet     TraceCategory = 1 << iota // Set or swap a default value.
+	TraceHookRegister                           // Register an init or exit function.
+	TraceHookRun                                // Run an init or exit function.
+	TraceCastFailure                            // Fail to cast a value.
+
+	TraceHooks = TraceHookRegister | TraceHookRun
+	TraceAll   = TraceValueSet | TraceHooks | TraceCastFailure
+)
+
+// tracenames is the names of the trace categories used by the env DEBUG.
+var tracenames = map[string]TraceCategory{
+	"all":   TraceAll,
+	"hooks": TraceHooks,
+
+	"set":           TraceValueSet,
+	"value-set":     TraceValueSet,
+	"register":      TraceHookRegister,
+	"hook-register": TraceHookRegister,
+	"run":           TraceHookRun,
+	"hook-run":      TraceHookRun,
+	"cast":          TraceCastFailure,
+	"cast-failure":  TraceCastFailure,
+}
+
+// String returns the names of the categories, separated by the comma.
+func (c TraceCategory) String() string {
+	names := make([]string, 0, 4)
+	if c&TraceValueSet != 0 {
+		names = append(names, "value-set")
+	}
+	if c&TraceHookRegister != 0 {
+		names = append(names, "hook-register")
+	}
+	if c&TraceHookRun != 0 {
+		names = append(names, "hook-run")
+	}
+	if c&TraceCastFailure != 0 {
+		names = append(names, "cast-failure")
+	}
+	return strings.Join(names, ",")
+}
+
+// TraceEvent is a structured trace event.
+type TraceEvent struct {
+	Time     time.Time
+	Category TraceCategory
+	Message  string
+	Caller   runtimex.Frame
+	Attrs    []slog.Attr
+}
+
+var (
+	tracecategories atomic.Uint32
+	traceconfigured atomic.Bool
+	tracesink       atomic.Pointer[func(TraceEvent)]
+)
+
+// TraceCategories returns the enabled trace categories,
+// which is parsed from the env DEBUG, such as "DEBUG=set,hooks".
+//
+// Default: 0, which means to disable the trace.
+func TraceCategories() TraceCategory {
+	return TraceCategory(tracecategories.Load())
+}
+
+// SetTraceCategories resets the enabled trace categories.
+//
+// After it is called, the trace categories take precedence over DEBUG,
+// so SetTraceCategories(0) disables all the trace categories.
+func SetTraceCategories(c TraceCategory) {
+	tracecategories.Store(uint32(c))
+	traceconfigured.Store(true)
+}
+
+// SetTraceSink sets the sink to consume the trace events, such as
+// collecting them in tests or sending them to the custom sink.
+//
+// If sink is nil, the event is logged by the logger with INFO, which is default.
+func SetTraceSink(sink func(TraceEvent)) {
+	if sink == nil {
+		tracesink.Store(nil)
+	} else {
+		tracesink.Store(&sink)
+	}
+}
+
+// Tracing reports whether the trace category c is enabled.
+//
+// If the trace categories are not configured by the env DEBUG
+// or SetTraceCategories, it falls back to DEBUG to enable all of them.
+func Tracing(c TraceCategory) bool {
+	if traceconfigured.Load() {
+		return TraceCategory(tracecategories.Load())&c != 0
+	}
+	return DEBUG
+}
+
+// Trace emits a trace event with the category c if it is enabled.
+//
+// skip is the number of the stack frames to skip to get the caller,
+// and 0 identifies the caller of Trace.
+func Trace(c TraceCategory, skip int, msg string, attrs ...slog.Attr) {
+	if Tracing(c) {
+		trace(c, runtimex.Caller(skip+1), msg, attrs)
+	}
+}
+
+func trace(c TraceCategory, caller runtimex.Frame, msg string, attrs []slog.Attr) {
+	event := TraceEvent{
+		Time:     time.Now(),
+		Category: c,
+		Message:  msg,
+		Caller:   caller,
+		Attrs:    attrs,
+	}
+
+	if sink := tracesink.Load(); sink != nil {
+		(*sink)(event)
+		return
+	}
+
+	attrs = append([]slog.Attr{
+		slog.String("category", c.String()),
+		slog.String("caller", caller.String()),
+	}, attrs...)
+	logger(context.Background()).LogAttrs(context.Background(), slog.LevelInfo, msg, attrs...)
+}
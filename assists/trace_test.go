@@ -0,0 +1,87 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assists
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseTraceCategories(t *testing.T) {
+	for s, expect := range map[string]TraceCategory{
+		"":                TraceCategory(0),
+		"false":           TraceCategory(0),
+		"true":            TraceAll,
+		"set,hooks":       TraceValueSet | TraceHooks,
+		" cast , unknown": TraceCastFailure,
+		"hook-run":        TraceHookRun,
+	} {
+		if c := parseTraceCategories(s); c != expect {
+			t.Errorf("%q: expect '%s', but got '%s'", s, expect, c)
+		}
+	}
+}
+
+func TestTraceSink(t *testing.T) {
+	var lock sync.Mutex
+	var events []TraceEvent
+	SetTraceSink(func(e TraceEvent) {
+		// Only collect the events of the hooks registered by this file.
+		if strings.HasSuffix(e.Caller.File, "trace_test.go") {
+			lock.Lock()
+			events = append(events, e)
+			lock.Unlock()
+		}
+	})
+	SetTraceCategories(TraceHookRun)
+	t.Cleanup(func() { SetTraceSink(nil); SetTraceCategories(0) })
+
+	l := NewLifecycle()
+	l.OnInit(func() {})
+	if err := l.RunInitContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expect %d trace event, but got %d", 1, len(events))
+	}
+
+	event := events[0]
+	if event.Category != TraceHookRun || !strings.HasPrefix(event.Message, "run init") {
+		t.Errorf("unexpected trace event: category=%s, msg=%s", event.Category, event.Message)
+	}
+}
+
+func TestTraceDisable(t *testing.T) {
+	categories, configured := tracecategories.Load(), traceconfigured.Load()
+	t.Cleanup(func() {
+		tracecategories.Store(categories)
+		traceconfigured.Store(configured)
+	})
+
+	initTraceCategories("set")
+	if !Tracing(TraceValueSet) || Tracing(TraceHookRun) {
+		t.Fatalf("expect only '%s' to be enabled, but got '%s'", TraceValueSet, TraceCategories())
+	}
+
+	SetTraceCategories(0)
+	for _, c := range []TraceCategory{TraceValueSet, TraceHookRegister, TraceHookRun, TraceCastFailure} {
+		if Tracing(c) {
+			t.Errorf("expect '%s' to be disabled, but it is enabled", c)
+		}
+	}
+}
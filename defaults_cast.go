@@ -25,47 +25,75 @@ import (
 
 var (
 	// ToBoolFunc is used to convert an input to bool.
-	ToBoolFunc = NewValueWithValidation(tobool, castValidation[bool]("ToBool"))
+	ToBoolFunc = newValueWithValidation("ToBoolFunc", tobool, castValidation[bool]("ToBool"))
 
 	// ToInt64Func is used to convert an input to int64.
-	ToInt64Func = NewValueWithValidation(toint64, castValidation[int64]("ToInt64"))
+	ToInt64Func = newValueWithValidation("ToInt64Func", toint64, castValidation[int64]("ToInt64"))
 
 	// ToUint64Func is used to convert an input to uint64.
-	ToUint64Func = NewValueWithValidation(touint64, castValidation[uint64]("ToUint64"))
+	ToUint64Func = newValueWithValidation("ToUint64Func", touint64, castValidation[uint64]("ToUint64"))
 
 	// ToFloat64Func is used to convert an input to float64.
-	ToFloat64Func = NewValueWithValidation(tofloat64, castValidation[float64]("ToFloat64"))
+	ToFloat64Func = newValueWithValidation("ToFloat64Func", tofloat64, castValidation[float64]("ToFloat64"))
 
 	// ToStringFunc is used to convert an input to string.
-	ToStringFunc = NewValueWithValidation(tostring, castValidation[string]("ToString"))
+	ToStringFunc = newValueWithValidation("ToStringFunc", tostring, castValidation[string]("ToString"))
 
 	// ToDurationFunc is used to convert an input to time.Duraiton.
-	ToDurationFunc = NewValueWithValidation(toduration, castValidation[time.Duration]("ToDuration"))
+	ToDurationFunc = newValueWithValidation("ToDurationFunc", toduration, castValidation[time.Duration]("ToDuration"))
 
 	// ToTimeFunc is used to convert an input to time.Time.
-	ToTimeFunc = NewValueWithValidation(totime, castValidation[time.Time]("ToTime"))
+	ToTimeFunc = newValueWithValidation("ToTimeFunc", totime, castValidation[time.Time]("ToTime"))
 )
 
 // ToBool is the proxy of ToBoolFunc to convert an input to bool.
-func ToBool(input any) (bool, error) { return ToBoolFunc.Get()(input) }
+func ToBool(input any) (v bool, err error) {
+	v, err = ToBoolFunc.Get()(input)
+	tracecast("ToBool", input, err)
+	return
+}
 
 // ToInt64 is the proxy of ToInt64Func to convert an input to int64.
-func ToInt64(input any) (int64, error) { return ToInt64Func.Get()(input) }
+func ToInt64(input any) (v int64, err error) {
+	v, err = ToInt64Func.Get()(input)
+	tracecast("ToInt64", input, err)
+	return
+}
 
 // ToUint64 is the proxy of ToUint64Func to convert an input to uint64.
-func ToUint64(input any) (uint64, error) { return ToUint64Func.Get()(input) }
+func ToUint64(input any) (v uint64, err error) {
+	v, err = ToUint64Func.Get()(input)
+	tracecast("ToUint64", input, err)
+	return
+}
 
 // ToFloat64 is the proxy of ToFloat64Func to convert an input to float64.
-func ToFloat64(input any) (float64, error) { return ToFloat64Func.Get()(input) }
+func ToFloat64(input any) (v float64, err error) {
+	v, err = ToFloat64Func.Get()(input)
+	tracecast("ToFloat64", input, err)
+	return
+}
 
 // ToString is the proxy of ToStringFunc to convert an input to string.
-func ToString(input any) (string, error) { return ToStringFunc.Get()(input) }
+func ToString(input any) (v string, err error) {
+	v, err = ToStringFunc.Get()(input)
+	tracecast("ToString", input, err)
+	return
+}
 
 // ToDuration is the proxy of ToDurationFunc to convert an input to time.Duration.
-func ToDuration(input any) (time.Duration, error) { return ToDurationFunc.Get()(input) }
+func ToDuration(input any) (v time.Duration, err error) {
+	v, err = ToDurationFunc.Get()(input)
+	tracecast("ToDuration", input, err)
+	return
+}
 
 // ToTime is the proxy of ToTimeFunc to convert an input to time.Time.
-func ToTime(input any) (time.Time, error) { return ToTimeFunc.Get()(input) }
+func ToTime(input any) (v time.Time, err error) {
+	v, err = ToTimeFunc.Get()(input)
+	tracecast("ToTime", input, err)
+	return
+}
 
 func tobool(src any) (dst bool, err error) {
 	switch src := src.(type) {
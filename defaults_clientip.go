@@ -37,8 +37,8 @@ var (
 	// 	interface{ RemoteAddr() net.Addr }
 	// 	interface{ RemoteAddr() string }
 	//
-	// Or, return nil.
-	GetClientIPFunc = NewValueWithValidation(getClientIP, fActxAifaceR1[netip.Addr]("GetClientIP"))
+	// Or, return the client ip stored in the context by WithClientIP.
+	GetClientIPFunc = newValueWithValidation("GetClientIPFunc", getClientIP, fActxAifaceR1[netip.Addr]("GetClientIP"))
 )
 
 // GetClientIP is the proxy of GetClientIPFunc to call the function.
@@ -73,5 +73,37 @@ func getClientIP(ctx context.Context, req any) (addr netip.Addr) {
 		addr, _ = netip.ParseAddr(host)
 	}
 
+	if !addr.IsValid() && ctx != nil {
+		addr = ClientIPFromContext(ctx)
+	}
+
 	return
 }
+
+type clientipkey struct{}
+
+// WithClientIP returns a new context carrying the client ip.
+func WithClientIP(ctx context.Context, ip netip.Addr) context.Context {
+	return context.WithValue(ctx, clientipkey{}, ip)
+}
+
+// ClientIPFromContext returns the client ip stored in the context
+// by WithClientIP. Or, return the invalid zero value.
+func ClientIPFromContext(ctx context.Context) netip.Addr {
+	ip, _ := ctx.Value(clientipkey{}).(netip.Addr)
+	return ip
+}
+
+// ClientIPHandler returns a http middleware handler, which stores
+// the client ip got by GetClientIP into the request context.
+//
+// So the later handlers can get it by GetClientIP(r.Context(), nil).
+func ClientIPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if ip := GetClientIP(ctx, r); ip.IsValid() {
+			r = r.WithContext(WithClientIP(ctx, ip))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+var (
+	// CrashReportDir is the directory into which the crash report file
+	// is written when calling Fatal or a panic is recovered by Recover.
+	//
+	// Default: "", which means to disable the crash report.
+	CrashReportDir = newValue("CrashReportDir", "")
+)
+
+// CrashReport is the report of a crash, such as Fatal or a panic.
+type CrashReport struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"` // "fatal" or "panic"
+	Message string    `json:"message"`
+
+	Args       map[string]string `json:"args,omitempty"`
+	Stacks     []runtimex.Frame  `json:"stacks,omitempty"`
+	Goroutines string            `json:"goroutines,omitempty"`
+
+	Pid       int            `json:"pid"`
+	GoVersion string         `json:"go_version"`
+	BuildInfo string         `json:"build_info,omitempty"`
+	Defaults  map[string]any `json:"defaults,omitempty"`
+}
+
+// WriteCrashReport writes the crash report into a new file
+// "crash-{pid}-{time}-{random}.json" in the directory CrashReportDir,
+// and returns the file path.
+//
+// If CrashReportDir is empty, do nothing and return "".
+func WriteCrashReport(kind, msg string, args []any, stacks []runtimex.Frame) (path string, err error) {
+	dir := CrashReportDir.Get()
+	if dir == "" {
+		return
+	}
+
+	report := CrashReport{
+		Time:       time.Now(),
+		Kind:       kind,
+		Message:    msg,
+		Args:       crashArgs(args),
+		Stacks:     stacks,
+		Goroutines: string(lookupProfile("goroutine", 2)),
+		Pid:        os.Getpid(),
+		GoVersion:  runtime.Version(),
+		Defaults:   diagnoseDefaults(),
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		report.BuildInfo = info.String()
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return
+	}
+
+	pattern := fmt.Sprintf("crash-%d-%s-*.json", report.Pid, report.Time.Format("20060102T150405"))
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return
+	}
+
+	_, err = file.Write(data)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		path = file.Name()
+	}
+	return
+}
+
+func writeCrashReport(kind, msg string, args []any, stacks []runtimex.Frame) {
+	if path, err := WriteCrashReport(kind, msg, args, stacks); err != nil {
+		Logger(context.Background()).Error("fail to write the crash report", "err", err)
+	} else if path != "" {
+		Logger(context.Background()).Info("write the crash report", "path", path)
+	}
+}
+
+// crashArgs converts the key-value pairs like slog into a map.
+func crashArgs(args []any) map[string]string {
+	if len(args) == 0 {
+		return nil
+	}
+
+	var r slog.Record
+	r.Add(args...)
+
+	attrs := make(map[string]string, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	return attrs
+}
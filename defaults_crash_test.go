@@ -0,0 +1,79 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestWriteCrashReport(t *testing.T) {
+	if path, err := WriteCrashReport("fatal", "msg", nil, nil); err != nil || path != "" {
+		t.Fatalf("expect no crash report, but got path=%q, err=%v", path, err)
+	}
+
+	CrashReportDir.Set(t.TempDir())
+	defer CrashReportDir.Set("")
+
+	path, err := WriteCrashReport("fatal", "msg", []any{"key", 123}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report CrashReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Kind != "fatal" || report.Message != "msg" {
+		t.Errorf("unexpected crash report: kind=%s, msg=%s", report.Kind, report.Message)
+	}
+	if v := report.Args["key"]; v != "123" {
+		t.Errorf("expect arg '%s', but got '%s'", "123", v)
+	}
+	if report.GoVersion == "" || len(report.Defaults) == 0 {
+		t.Errorf("expect the go version and defaults, but got nothing")
+	}
+
+	resetPanicStats()
+	for i := 0; i < 3; i++ { // Only write the report for the first panic.
+		func() {
+			defer Recover(context.Background())
+			panic("test")
+		}()
+	}
+	_ = SafeCall(context.Background(), func() error { panic("safecall") })
+
+	handler := RecoverHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("handler")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	files, err := os.ReadDir(CrashReportDir.Get())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 4 {
+		t.Errorf("expect %d crash reports, but got %d", 4, len(files))
+	}
+}
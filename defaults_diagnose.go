@@ -0,0 +1,178 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+var (
+	// DiagnoseDir is the directory into which Diagnose writes the files.
+	//
+	// Default: "", which means to emit the diagnostic information by slog.
+	DiagnoseDir = newValue("DiagnoseDir", "")
+)
+
+// EnableDiagnoseSignal is used to enable to call Diagnose
+// when the user signal sig, such as syscall.SIGUSR1, occurs.
+//
+// NOTICE: sig must be contained in UserSignals,
+// and SignalForUser must be running.
+func EnableDiagnoseSignal(sig os.Signal) {
+	OnUserSignal(func(s os.Signal) {
+		if s == sig {
+			if err := Diagnose(); err != nil {
+				Logger(context.Background()).Error("fail to diagnose", "err", err)
+			}
+		}
+	})
+}
+
+// Diagnose dumps the diagnostic information of the program without
+// killing it, which contains the stacks of all the goroutines,
+// the heap profile and the current defaults.
+//
+// If DiagnoseDir is not empty, they are written into the files
+// "diagnosis-{pid}-{time}.{goroutine.txt,heap.pprof,defaults.json}"
+// in the directory. Or, they are emitted by slog.
+func Diagnose() (err error) {
+	values := diagnoseDefaults()
+	dir := DiagnoseDir.Get()
+	if dir == "" {
+		Logger(context.Background()).Warn("diagnosis",
+			"goroutines", string(lookupProfile("goroutine", 2)),
+			"heap", string(lookupProfile("heap", 1)),
+			"defaults", values)
+		return
+	}
+
+	name := fmt.Sprintf("diagnosis-%d-%s", os.Getpid(), time.Now().Format("20060102T150405.000"))
+	prefix := filepath.Join(dir, name)
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return
+	}
+
+	err = errors.Join(
+		os.WriteFile(prefix+".goroutine.txt", lookupProfile("goroutine", 2), 0o644),
+		os.WriteFile(prefix+".heap.pprof", lookupProfile("heap", 0), 0o644),
+		os.WriteFile(prefix+".defaults.json", data, 0o644),
+	)
+
+	if err == nil {
+		Logger(context.Background()).Info("write the diagnosis files", "prefix", prefix)
+	}
+	return
+}
+
+func lookupProfile(name string, debug int) []byte {
+	var buf bytes.Buffer
+	if p := pprof.Lookup(name); p != nil {
+		_ = p.WriteTo(&buf, debug)
+	}
+	return buf.Bytes()
+}
+
+func init() {
+	// The defaults which are not Values.
+	diagnoseFunc("DEBUG", func() any { return assists.DEBUG })
+	diagnoseFunc("TraceCategories", func() any { return assists.TraceCategories().String() })
+	diagnoseFunc("ExitSignals", func() any { return diagnoseValue(ExitSignals()) })
+	diagnoseFunc("ReloadSignals", func() any { return diagnoseValue(ReloadSignals()) })
+	diagnoseFunc("UserSignals", func() any { return diagnoseValue(UserSignals()) })
+	diagnoseFunc("HeaderXRequestID", func() any { return HeaderXRequestID })
+	diagnoseFunc("HeaderXForwardedFor", func() any { return HeaderXForwardedFor })
+	diagnoseFunc("LogKeyRequestID", func() any { return LogKeyRequestID })
+	diagnoseFunc("LogKeyClientIP", func() any { return LogKeyClientIP })
+}
+
+// diagnoses is the registered defaults dumped by Diagnose, which are
+// registered by newValue and newValueWithValidation when initializing.
+var diagnoses = make(map[string]func() any, 64)
+
+// diagnose registers the default value named name to be dumped by Diagnose.
+func diagnose[T any](name string, v *Value[T]) {
+	diagnoseFunc(name, func() any { return diagnoseValue(v.Get()) })
+}
+
+// diagnoseFunc registers the function to get the default named name,
+// which is dumped by Diagnose.
+func diagnoseFunc(name string, get func() any) {
+	if _, ok := diagnoses[name]; ok {
+		panic(fmt.Errorf("the diagnosed default '%s' has been registered", name))
+	}
+	diagnoses[name] = get
+}
+
+func diagnoseDefaults() map[string]any {
+	values := make(map[string]any, len(diagnoses))
+	for name, get := range diagnoses {
+		values[name] = get()
+	}
+	return values
+}
+
+// diagnoseValue converts the value to a json-friendly one,
+// such as the function to its name.
+func diagnoseValue(v any) any {
+	switch _v := v.(type) {
+	case nil:
+		return nil
+
+	case fmt.Stringer:
+		return _v.String()
+
+	case []os.Signal:
+		sigs := make([]string, len(_v))
+		for i, sig := range _v {
+			sigs[i] = sig.String()
+		}
+		return sigs
+
+	case []RequestIDHeader:
+		names := make([]string, len(_v))
+		for i, h := range _v {
+			names[i] = h.Name
+		}
+		return names
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Func:
+		if rv.IsNil() {
+			return nil
+		}
+		return runtime.FuncForPC(rv.Pointer()).Name()
+
+	case reflect.Interface, reflect.Pointer, reflect.Struct:
+		return fmt.Sprintf("%T", v)
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiagnoseDefaults(t *testing.T) {
+	values := diagnoseDefaults()
+	if _, err := json.Marshal(values); err != nil {
+		t.Fatalf("fail to marshal the defaults: %v", err)
+	}
+
+	for _, name := range []string{"ExitTimeout", "FatalLogger", "RequestIDHeaders", "DEBUG", "ExitSignals"} {
+		if _, ok := values[name]; !ok {
+			t.Errorf("the default '%s' is not registered for diagnosis", name)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expect a panic for the duplicate default, but got nil")
+		}
+	}()
+	newValue("ExitTimeout", 0)
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+var (
+	// GoWaitTimeout is the timeout to wait for the goroutines started by Go
+	// to finish when the program exits, which is also limited by
+	// the timeout of the exit functions.
+	//
+	// Default: 0, which means to wait until all the goroutines finish.
+	GoWaitTimeout = newValue("GoWaitTimeout", time.Duration(0))
+)
+
+type goroutine struct {
+	name  string
+	start time.Time
+	done  chan struct{}
+}
+
+var (
+	golock     sync.Mutex
+	gowait     assists.Handle
+	goroutines = make(map[*goroutine]struct{}, 8)
+)
+
+// Go starts a managed goroutine named name to run the function f
+// with the exit context, which recovers and handles the panic by HandlePanic.
+//
+// When the program exits, the exit functions wait for all the managed
+// goroutines to finish until GoWaitTimeout is exceeded, then log
+// the goroutines still running.
+func Go(name string, f func(context.Context)) {
+	if f == nil {
+		panic("Go: the goroutine function must not be nil")
+	}
+
+	g := &goroutine{name: name, start: time.Now(), done: make(chan struct{})}
+	golock.Lock()
+	goroutines[g] = struct{}{}
+	if !gowait.Registered() {
+		// Register it lazily, and again after the lifecycle is reset,
+		// to wait for the goroutines after the main function of Run returns.
+		gowait = assists.Register(assists.StageExit, assists.Hook{Name: "defaults.Go", Priority: math.MinInt + 1},
+			func(ctx context.Context) error { waitGoroutines(ctx); return nil })
+	}
+	golock.Unlock()
+
+	ctx := ExitContext()
+	go func() {
+		defer func() {
+			golock.Lock()
+			delete(goroutines, g)
+			golock.Unlock()
+			close(g.done)
+		}()
+
+		defer Recover(ctx)
+		f(ctx)
+	}()
+}
+
+// RunningGoroutines returns the names of the managed goroutines
+// started by Go, which are still running, sorted by the start time.
+func RunningGoroutines() []string {
+	gs := runningGoroutines()
+	names := make([]string, len(gs))
+	for i, g := range gs {
+		names[i] = g.name
+	}
+	return names
+}
+
+func runningGoroutines() []*goroutine {
+	golock.Lock()
+	gs := make([]*goroutine, 0, len(goroutines))
+	for g := range goroutines {
+		gs = append(gs, g)
+	}
+	golock.Unlock()
+
+	sort.Slice(gs, func(i, j int) bool { return gs[i].start.Before(gs[j].start) })
+	return gs
+}
+
+func waitGoroutines(ctx context.Context) {
+	if timeout := GoWaitTimeout.Get(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		gs := runningGoroutines()
+		if len(gs) == 0 {
+			return
+		}
+
+		for _, g := range gs {
+			select {
+			case <-g.done:
+			case <-ctx.Done():
+				logStragglers()
+				return
+			}
+		}
+	}
+}
+
+func logStragglers() {
+	gs := runningGoroutines()
+	stragglers := make([]string, len(gs))
+	for i, g := range gs {
+		stragglers[i] = g.name + "(" + time.Since(g.start).String() + ")"
+	}
+	Logger(context.Background()).Error("timeout to wait for the managed goroutines", "stragglers", stragglers)
+}
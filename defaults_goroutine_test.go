@@ -0,0 +1,48 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+func TestGo(t *testing.T) {
+	assists.DefaultLifecycle.Reset() // The wait hook must be registered again.
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	var finished atomic.Bool
+	Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(time.Millisecond * 20)
+		finished.Store(true)
+	})
+
+	if names := RunningGoroutines(); len(names) != 1 || names[0] != "worker" {
+		t.Errorf("expect the running goroutine 'worker', but got %v", names)
+	}
+
+	assists.RunExit()
+	if !finished.Load() {
+		t.Errorf("expect the goroutine is awaited on exit")
+	}
+	if names := RunningGoroutines(); len(names) != 0 {
+		t.Errorf("expect no running goroutines, but got %v", names)
+	}
+}
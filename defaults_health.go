@@ -0,0 +1,163 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+type healthcheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+var (
+	healthlock   sync.RWMutex
+	healthchecks []*healthcheck
+)
+
+// OnHealthCheck registers the health check of the component named name,
+// which is used by the readiness handler.
+//
+// If the name has been registered, the old check is replaced,
+// and cancelling the handle of the old one has no effect.
+//
+// The returned handle may be used to remove the health check.
+func OnHealthCheck(name string, check func(context.Context) error) Handle {
+	if name == "" {
+		panic("OnHealthCheck: the component name must not be empty")
+	} else if check == nil {
+		panic("OnHealthCheck: the health check function must not be nil")
+	}
+
+	hc := &healthcheck{name: name, check: check}
+	handle := Handle{cancel: func() { removeHealthCheck(hc) }}
+
+	healthlock.Lock()
+	defer healthlock.Unlock()
+
+	// Copy on write, because CheckHealth calls the checks without the lock.
+	checks := make([]*healthcheck, len(healthchecks), len(healthchecks)+1)
+	copy(checks, healthchecks)
+	for i := range checks {
+		if checks[i].name == name {
+			checks[i] = hc
+			healthchecks = checks
+			return handle
+		}
+	}
+	healthchecks = append(checks, hc)
+	return handle
+}
+
+func removeHealthCheck(hc *healthcheck) {
+	healthlock.Lock()
+	defer healthlock.Unlock()
+	for i, _hc := range healthchecks {
+		if _hc == hc {
+			healthchecks = append(healthchecks[:i:i], healthchecks[i+1:]...)
+			return
+		}
+	}
+}
+
+// CheckHealth calls all the registered health checks in turn,
+// and returns the errors of the failed components by the name.
+func CheckHealth(ctx context.Context) (errs map[string]error) {
+	healthlock.RLock()
+	checks := healthchecks
+	healthlock.RUnlock()
+
+	for _, c := range checks {
+		if err := c.check(ctx); err != nil {
+			if errs == nil {
+				errs = make(map[string]error, len(checks))
+			}
+			errs[c.name] = err
+		}
+	}
+	return
+}
+
+// HealthHandler returns a http handler to serve the liveness
+// and readiness probes, which dispatches the request whose path
+// has the suffix "/healthz" to LivenessHandler and "/readyz"
+// to ReadinessHandler, or responds 404.
+func HealthHandler() http.Handler {
+	liveness, readiness := LivenessHandler(), ReadinessHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+		case strings.HasSuffix(path, "/healthz"):
+			liveness.ServeHTTP(w, r)
+		case strings.HasSuffix(path, "/readyz"):
+			readiness.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// LivenessHandler returns a http handler to serve the liveness probe,
+// which responds 200 unless the lifecycle state is stopped.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := assists.GetState()
+		writeHealth(w, state != assists.StateStopped, state, nil)
+	})
+}
+
+// ReadinessHandler returns a http handler to serve the readiness probe,
+// which responds 200 only if the lifecycle state is ready and all
+// the health checks registered by OnHealthCheck succeed, or 503.
+func ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := assists.GetState()
+		if state != assists.StateReady {
+			writeHealth(w, false, state, nil)
+			return
+		}
+
+		errs := CheckHealth(r.Context())
+		writeHealth(w, len(errs) == 0, state, errs)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, ok bool, state assists.State, errs map[string]error) {
+	result := struct {
+		State  string            `json:"state"`
+		Errors map[string]string `json:"errors,omitempty"`
+	}{State: state.String()}
+
+	if len(errs) > 0 {
+		result.Errors = make(map[string]string, len(errs))
+		for name, err := range errs {
+			result.Errors[name] = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
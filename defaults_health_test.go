@@ -0,0 +1,98 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+func TestOnHealthCheckReplace(t *testing.T) {
+	errdb := errors.New("db")
+	old := OnHealthCheck("replace", func(context.Context) error { return errdb })
+	if errs := CheckHealth(context.Background()); errs["replace"] != errdb {
+		t.Errorf("expect the error '%v', but got '%v'", errdb, errs["replace"])
+	}
+
+	h := OnHealthCheck("replace", func(context.Context) error { return nil })
+	t.Cleanup(h.Cancel)
+	if errs := CheckHealth(context.Background()); errs["replace"] != nil {
+		t.Errorf("expect no error, but got '%v'", errs["replace"])
+	}
+
+	if count := countHealthCheck("replace"); count != 1 {
+		t.Errorf("expect %d health check, but got %d", 1, count)
+	}
+
+	// Cancelling the replaced check must not remove the new one.
+	old.Cancel()
+	if n := countHealthCheck("replace"); n != 1 {
+		t.Errorf("expect %d health check, but got %d", 1, n)
+	}
+
+	h.Cancel()
+	if n := countHealthCheck("replace"); n != 0 {
+		t.Errorf("expect %d health check, but got %d", 0, n)
+	}
+}
+
+func countHealthCheck(name string) (count int) {
+	healthlock.RLock()
+	defer healthlock.RUnlock()
+	for _, c := range healthchecks {
+		if c.name == name {
+			count++
+		}
+	}
+	return
+}
+
+func TestReadinessHandler(t *testing.T) {
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	serve := func() (int, string) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		HealthHandler().ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	if code, body := serve(); code != http.StatusServiceUnavailable || !strings.Contains(body, `"starting"`) {
+		t.Errorf("expect the starting state with 503, but got %d: %s", code, body)
+	}
+
+	if err := assists.RunInitContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	h := OnHealthCheck("readiness", func(context.Context) error { return err })
+	t.Cleanup(h.Cancel)
+	if code, body := serve(); code != http.StatusOK || !strings.Contains(body, `"ready"`) {
+		t.Errorf("expect the ready state with 200, but got %d: %s", code, body)
+	}
+
+	err = errors.New("unavailable")
+	if code, body := serve(); code != http.StatusServiceUnavailable || !strings.Contains(body, `"readiness":"unavailable"`) {
+		t.Errorf("expect the failed check with 503, but got %d: %s", code, body)
+	}
+	err = nil
+}
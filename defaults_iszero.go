@@ -22,7 +22,7 @@ var (
 	// For the default implementation, it also supports
 	//    interface{ IsZero() bool }
 	//
-	IsZeroFunc = NewValueWithValidation(runtimex.IsZero, fA1R1Validation[any, bool]("IsZero"))
+	IsZeroFunc = newValueWithValidation("IsZeroFunc", runtimex.IsZero, fA1R1Validation[any, bool]("IsZero"))
 )
 
 // IsZero is the proxy of IsZeroFunc to call it.
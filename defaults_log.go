@@ -16,22 +16,165 @@ package defaults
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+
+	"github.com/xgfone/go-defaults/assists"
+	"github.com/xgfone/go-toolkit/runtimex"
 )
 
+// ExitCoder is an interface to return the exit code of the program,
+// which may be implemented by an error, such as SignalError.
+type ExitCoder interface {
+	ExitCode() int
+}
+
 var (
+	// LoggerFunc is used to get the logger by the context, which is used
+	// to log the messages in this package and the package assists,
+	// and is the proxy of assists.LoggerFunc.
+	//
+	// Default: return slog.Default()
+	LoggerFunc = newProxyValue("LoggerFunc", assists.LoggerFunc, assists.SetLoggerFunc,
+		fA1R1Validation[context.Context, *slog.Logger]("Logger"))
+
+	// FatalLevel is the level used by the default FatalContextFunc.
+	//
+	// Default: slog.LevelError+4
+	FatalLevel = newValue("FatalLevel", slog.LevelError+4)
+
+	// FatalExitCode is the exit code used by the default FatalContextFunc
+	// when no error in the arguments implements ExitCoder.
+	//
+	// Default: 1
+	FatalExitCode = newValue("FatalExitCode", 1)
+
+	// FatalLogger is the logger used by the default FatalContextFunc.
+	//
+	// Default: nil, which means to use Logger(ctx).
+	FatalLogger = newValue[*slog.Logger]("FatalLogger", nil)
+
 	// FatalFunc is used to log the message with the FATAL level,
 	// then the program exits.
 	//
-	// Default: use slog.LevelError+4 as the FATAL level, then exit by Exit(1).
-	FatalFunc = NewValueWithValidation(fatal, fA2Validation[string, []any]("Fatal"))
+	// Default: call FatalContext with context.Background().
+	FatalFunc = newValueWithValidation("FatalFunc", fatal, fA2Validation[string, []any]("Fatal"))
+
+	// FatalContextFunc is used to log the message with the FATAL level
+	// and the context, then the program exits.
+	//
+	// Default: log the message by FatalLogger with FatalLevel, then exit
+	// by Exit with the exit code of the first error in args implementing
+	// ExitCoder, or FatalExitCode if not found.
+	FatalContextFunc = newValueWithValidation("FatalContextFunc", fatalContext,
+		fA3Validation[context.Context, string, []any]("FatalContext"))
 )
 
+// Logger is the proxy of LoggerFunc to get the logger by the context.
+//
+// If ctx is nil, use context.Background() instead.
+func Logger(ctx context.Context) *slog.Logger {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return LoggerFunc.Get()(ctx)
+}
+
 // Fatal is the proxy of FatalFunc to log the message with the FATAL level,
 // then the program exits.
-func Fatal(msg string, args ...any) { FatalFunc.Get()(msg, args) }
+//
+// If CrashReportDir is set, write the crash report before calling FatalFunc.
+func Fatal(msg string, args ...any) {
+	writeCrashReport("fatal", msg, args, runtimex.Stacks(1))
+	FatalFunc.Get()(msg, args)
+}
+
+// FatalContext is the proxy of FatalContextFunc to log the message
+// with the FATAL level and the context, then the program exits.
+//
+// If CrashReportDir is set, write the crash report before calling FatalContextFunc.
+func FatalContext(ctx context.Context, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	writeCrashReport("fatal", msg, args, runtimex.Stacks(1))
+	FatalContextFunc.Get()(ctx, msg, args)
+}
+
+// FatalErr logs the error with the FATAL level, then the program exits.
+//
+// The error and the errors wrapped by it are used as the structured
+// attributes, and the exit code is got from the first error in the chain
+// implementing ExitCoder. If err is nil, do nothing.
+func FatalErr(err error) {
+	if err == nil {
+		return
+	}
+
+	args := fatalErrArgs(err)
+	writeCrashReport("fatal", err.Error(), args, runtimex.Stacks(1))
+	FatalContextFunc.Get()(context.Background(), err.Error(), args)
+}
 
 func fatal(msg string, args []any) {
-	slog.Log(context.Background(), slog.LevelError+4, msg, args...)
-	Exit(1)
+	FatalContextFunc.Get()(context.Background(), msg, args)
+}
+
+func fatalContext(ctx context.Context, msg string, args []any) {
+	log := FatalLogger.Get()
+	if log == nil {
+		log = Logger(ctx)
+	}
+
+	log.Log(ctx, FatalLevel.Get(), msg, args...)
+	Exit(fatalExitCode(args))
+}
+
+// fatalExitCode returns the exit code of the first error in args
+// implementing ExitCoder, or FatalExitCode if not found.
+func fatalExitCode(args []any) int {
+	var coder ExitCoder
+	for _, arg := range args {
+		if attr, ok := arg.(slog.Attr); ok {
+			arg = attr.Value.Any()
+		}
+
+		if err, ok := arg.(error); ok && errors.As(err, &coder) {
+			return coder.ExitCode()
+		}
+	}
+	return FatalExitCode.Get()
+}
+
+// fatalErrArgs returns the structured attributes of the error,
+// which contains the error itself, its type and the wrapped errors.
+func fatalErrArgs(err error) []any {
+	args := []any{slog.Any("err", err), slog.String("errtype", fmt.Sprintf("%T", err))}
+	if causes := unwrapErrors(err); len(causes) > 0 {
+		args = append(args, slog.Any("causes", causes))
+	}
+	return args
+}
+
+// unwrapErrors returns the messages of all the errors wrapped by err.
+func unwrapErrors(err error) (causes []string) {
+	var errs []error
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if ue := e.Unwrap(); ue != nil {
+			errs = []error{ue}
+		}
+
+	case interface{ Unwrap() []error }:
+		errs = e.Unwrap()
+	}
+
+	for _, e := range errs {
+		if e != nil {
+			causes = append(causes, e.Error())
+			causes = append(causes, unwrapErrors(e)...)
+		}
+	}
+	return
 }
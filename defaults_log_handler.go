@@ -0,0 +1,135 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"log/slog"
+)
+
+var (
+	// LogKeyRequestID is the attribute key of the request id
+	// added by the log handler returned by NewContextLogHandler.
+	LogKeyRequestID = "request_id"
+
+	// LogKeyClientIP is the attribute key of the client ip
+	// added by the log handler returned by NewContextLogHandler.
+	LogKeyClientIP = "client_ip"
+)
+
+// NewContextLogHandler returns a new slog.Handler wrapping the handler h,
+// which adds the attributes, LogKeyRequestID and LogKeyClientIP,
+// derived by GetRequestID(ctx, nil) and GetClientIP(ctx, nil)
+// for any record logged with a context.
+//
+// The attributes are always added at the top level, even if the logger
+// has opened the groups by WithGroup, so that they can be correlated.
+// For this, the groups and the attributes after the first group
+// are kept by the returned handler and added into the record as the group
+// attributes, instead of being passed to h by WithGroup and WithAttrs.
+//
+// Example:
+//
+//	handler := slog.NewJSONHandler(os.Stderr, nil)
+//	slog.SetDefault(slog.New(NewContextLogHandler(handler)))
+func NewContextLogHandler(h slog.Handler) slog.Handler {
+	if h == nil {
+		panic("NewContextLogHandler: slog handler must not be nil")
+	}
+	return contextLogHandler{Handler: h}
+}
+
+type contextLogHandler struct {
+	slog.Handler // The handler before opening the first group.
+
+	// The groups and attributes after opening the first group.
+	goas []groupOrAttrs
+}
+
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (h contextLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := contextLogAttrs(ctx)
+	if len(h.goas) == 0 {
+		if len(attrs) > 0 {
+			r = r.Clone()
+			r.AddAttrs(attrs...)
+		}
+		return h.Handler.Handle(ctx, r)
+	}
+
+	// Add the context attributes at the top level, and the others in the groups.
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	record.AddAttrs(attrs...)
+	record.AddAttrs(h.groupAttrs(r)...)
+	return h.Handler.Handle(ctx, record)
+}
+
+func contextLogAttrs(ctx context.Context) (attrs []slog.Attr) {
+	if ctx == nil {
+		return
+	}
+
+	if id := GetRequestID(ctx, nil); id != "" {
+		attrs = append(attrs, slog.String(LogKeyRequestID, id))
+	}
+
+	if ip := GetClientIP(ctx, nil); ip.IsValid() {
+		attrs = append(attrs, slog.String(LogKeyClientIP, ip.String()))
+	}
+
+	return
+}
+
+// groupAttrs nests the attributes of the record r and h.goas into the groups.
+func (h contextLogHandler) groupAttrs(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool { attrs = append(attrs, a); return true })
+
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		if goa := h.goas[i]; goa.group == "" {
+			attrs = append(goa.attrs[:len(goa.attrs):len(goa.attrs)], attrs...)
+		} else {
+			attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	return attrs
+}
+
+func (h contextLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	switch {
+	case len(attrs) == 0:
+		return h
+	case len(h.goas) == 0:
+		return contextLogHandler{Handler: h.Handler.WithAttrs(attrs)}
+	default:
+		return h.with(groupOrAttrs{attrs: attrs})
+	}
+}
+
+func (h contextLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h contextLogHandler) with(goa groupOrAttrs) contextLogHandler {
+	goas := append(h.goas[:len(h.goas):len(h.goas)], goa)
+	return contextLogHandler{Handler: h.Handler, goas: goas}
+}
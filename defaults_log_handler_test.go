@@ -0,0 +1,81 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestContextLogHandler(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := slog.New(NewContextLogHandler(slog.NewTextHandler(buf, nil)))
+
+	ip := netip.MustParseAddr("127.0.0.1")
+	tests := []struct {
+		group  string
+		ctx    context.Context
+		expect []string
+		absent []string
+	}{
+		{
+			ctx:    context.Background(),
+			absent: []string{LogKeyRequestID, LogKeyClientIP},
+		},
+		{
+			ctx:    WithRequestID(context.Background(), "abc"),
+			expect: []string{LogKeyRequestID + "=abc"},
+			absent: []string{LogKeyClientIP},
+		},
+		{
+			ctx:    WithClientIP(WithRequestID(context.Background(), "abc"), ip),
+			expect: []string{LogKeyRequestID + "=abc", LogKeyClientIP + "=127.0.0.1"},
+		},
+		{
+			group:  "g",
+			ctx:    WithClientIP(WithRequestID(context.Background(), "abc"), ip),
+			expect: []string{" " + LogKeyRequestID + "=abc", " " + LogKeyClientIP + "=127.0.0.1", "g.k=1"},
+			absent: []string{"g." + LogKeyRequestID, "g." + LogKeyClientIP},
+		},
+	}
+
+	for i, test := range tests {
+		buf.Reset()
+		key, log := "key=value", logger
+		if test.group != "" {
+			key, log = test.group+"."+key, log.WithGroup(test.group)
+		}
+		log.With("key", "value").InfoContext(test.ctx, "msg", "k", 1)
+
+		line := buf.String()
+		if !strings.Contains(line, key) {
+			t.Errorf("%d: expect the attribute of With, but got '%s'", i, line)
+		}
+		for _, s := range test.expect {
+			if !strings.Contains(line, s) {
+				t.Errorf("%d: expect '%s', but got '%s'", i, s, line)
+			}
+		}
+		for _, s := range test.absent {
+			if strings.Contains(line, s+"=") {
+				t.Errorf("%d: unexpect '%s', but got '%s'", i, s, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+type exitCodeError struct{ code int }
+
+func (e exitCodeError) Error() string { return "exitcode" }
+func (e exitCodeError) ExitCode() int { return e.code }
+
+func TestFatalErr(t *testing.T) {
+	var code int
+	ExitFunc.Set(func(c int) { code = c })
+	defer ExitFunc.Set(exit)
+
+	buf := bytes.NewBuffer(nil)
+	FatalLogger.Set(slog.New(slog.NewTextHandler(buf, nil)))
+	defer FatalLogger.Set(nil)
+
+	FatalErr(fmt.Errorf("wrap: %w", exitCodeError{code: 5}))
+	if code != 5 {
+		t.Errorf("expect exit code %d, but got %d", 5, code)
+	}
+	if s := buf.String(); !strings.Contains(s, "level=ERROR+4") || !strings.Contains(s, "causes=[exitcode]") {
+		t.Errorf("unexpected fatal log: %s", s)
+	}
+
+	FatalLevel.Set(slog.LevelError)
+	defer FatalLevel.Set(slog.LevelError + 4)
+
+	buf.Reset()
+	Fatal("msg", "err", errors.New("test"))
+	if code != 1 {
+		t.Errorf("expect exit code %d, but got %d", 1, code)
+	}
+	if s := buf.String(); !strings.Contains(s, "level=ERROR ") {
+		t.Errorf("unexpected fatal log: %s", s)
+	}
+}
+
+func TestLoggerFunc(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	log := slog.New(slog.NewTextHandler(buf, nil))
+	defer LoggerFunc.Set(LoggerFunc.Swap(func(context.Context) *slog.Logger { return log }))
+	if l := assists.LoggerFunc()(context.Background()); l != log {
+		t.Errorf("expect assists.LoggerFunc to return the swapped logger")
+	}
+
+	func() {
+		defer Recover(context.Background())
+		panic("logger")
+	}()
+
+	if s := buf.String(); !strings.Contains(s, "wrap a panic") {
+		t.Errorf("expect the panic log, but got '%s'", s)
+	}
+}
@@ -25,17 +25,18 @@ var (
 	// ExitFunc is used to exit the program.
 	//
 	// Default: calling assists.RunExit and os.Exit in turn.
-	ExitFunc = NewValueWithValidation(exit, fA1Validation[int]("Exit"))
+	ExitFunc = newValueWithValidation("ExitFunc", exit, fA1Validation[int]("Exit"))
 
 	// ExitWaitFunc is used to wait until the program exit.
 	//
 	// Default: assists.WaitExit
-	ExitWaitFunc = NewValueWithValidation(assists.WaitExit, fValidation("ExitWait"))
+	ExitWaitFunc = newValueWithValidation("ExitWaitFunc", assists.WaitExit, fValidation("ExitWait"))
 
 	// ExitContextFunc is used to get the exit context.
 	//
 	// Default: assists.ExitContext
-	ExitContextFunc = NewValueWithValidation(assists.ExitContext, fR1Validation[context.Context]("ExitContext"))
+	ExitContextFunc = newValueWithValidation("ExitContextFunc", assists.ExitContext,
+		fR1Validation[context.Context]("ExitContext"))
 
 	// ExitSignalsFunc is used to get the signals to let the program exit.
 	//
@@ -48,7 +49,33 @@ var (
 	//	syscall.SIGINT
 	//
 	// On others, it only contains the signal os.Interrupt.
-	ExitSignalsFunc = NewValueWithValidation(exitSignalsFunc, fR1Validation[[]os.Signal]("ExitSignals"))
+	ExitSignalsFunc = newValueWithValidation("ExitSignalsFunc", exitSignalsFunc,
+		fR1Validation[[]os.Signal]("ExitSignals"))
+
+	// ExitTimeout is the overall deadline to call all the exit functions,
+	// which is the proxy of assists.ExitTimeout.
+	//
+	// Default: 0, which means no deadline.
+	ExitTimeout = newProxyValue("ExitTimeout", assists.ExitTimeout, assists.SetExitTimeout, nil)
+
+	// ExitHookTimeout is the default timeout to call each exit function,
+	// which is the proxy of assists.ExitHookTimeout.
+	//
+	// Default: 0, which means no timeout.
+	ExitHookTimeout = newProxyValue("ExitHookTimeout", assists.ExitHookTimeout, assists.SetExitHookTimeout, nil)
+
+	// ExitDrainDelay is the delay to wait in the draining state, during which
+	// the readiness fails, before calling the exit functions, which is
+	// the proxy of assists.ExitDrainDelay.
+	//
+	// Default: 0, which means no delay.
+	ExitDrainDelay = newProxyValue("ExitDrainDelay", assists.ExitDrainDelay, assists.SetExitDrainDelay, nil)
+
+	// ExitFlushDelay is the delay to wait for the final flush after calling
+	// all the exit functions, which is the proxy of assists.ExitFlushDelay.
+	//
+	// Default: 0
+	ExitFlushDelay = newProxyValue("ExitFlushDelay", assists.ExitFlushDelay, assists.SetExitFlushDelay, nil)
 )
 
 func exit(code int) {
@@ -72,7 +99,27 @@ func exitSignalsFunc() []os.Signal { return exitsignals }
 func ExitWait() { ExitWaitFunc.Get()() }
 
 // OnExit registers the exit function f, which is the proxy of assists.OnExit.
-func OnExit(f func()) { assists.OnExit(f) }
+func OnExit(f func()) assists.Handle { return register(1, assists.StageExit, assists.Hook{}, f) }
 
 // OnExitPost registers the post-exit function f, which is the proxy of assists.OnExitPost.
-func OnExitPost(f func()) { assists.OnExitPost(f) }
+func OnExitPost(f func()) assists.Handle {
+	return register(1, assists.StageExitPost, assists.Hook{}, f)
+}
+
+// OnExitCtx registers the exit function f receiving a context with
+// the deadline, which is the proxy of assists.OnExitCtx.
+func OnExitCtx(f func(context.Context)) assists.Handle {
+	return register(1, assists.StageExit, assists.Hook{}, f)
+}
+
+// OnExitPostCtx registers the post-exit function f receiving a context with
+// the deadline, which is the proxy of assists.OnExitPostCtx.
+func OnExitPostCtx(f func(context.Context)) assists.Handle {
+	return register(1, assists.StageExitPost, assists.Hook{}, f)
+}
+
+// OnExitFlush registers the flusher f called after calling the post-exit
+// functions, which is the proxy of assists.OnExitFlush.
+func OnExitFlush(f assists.Flusher) assists.Handle {
+	return register(1, assists.StageExitFlush, assists.Hook{}, f)
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+func TestOnExitCaller(t *testing.T) {
+	handles := []assists.Handle{
+		OnExit(func() {}),
+		Register(assists.StageExit, assists.Hook{Name: "caller"}, func(context.Context) error { return nil }),
+		OnExitCtx(func(context.Context) {}),
+		OnInit(func() {}),
+		OnInitE(func(context.Context) error { return nil }),
+		assists.OnExitPost(func() {}),
+		assists.DefaultLifecycle.OnInit(func() {}),
+	}
+
+	for i, handle := range handles {
+		handle.Cancel()
+		if caller := handle.Caller(); !strings.HasSuffix(caller.File, "defaults_onexit_test.go") {
+			t.Errorf("%d: expect the caller in the test file, but got '%s'", i, caller)
+		}
+	}
+}
+
+func TestExitTimeoutProxy(t *testing.T) {
+	defer ExitTimeout.Set(ExitTimeout.Get())
+
+	ExitTimeout.Set(time.Second)
+	if timeout := assists.ExitTimeout(); timeout != time.Second {
+		t.Errorf("expect assists.ExitTimeout %s, but got %s", time.Second, timeout)
+	}
+
+	if old := ExitTimeout.Swap(time.Minute); old != time.Second {
+		t.Errorf("expect the old timeout %s, but got %s", time.Second, old)
+	} else if timeout := assists.ExitTimeout(); timeout != time.Minute {
+		t.Errorf("expect assists.ExitTimeout %s, but got %s", time.Minute, timeout)
+	}
+
+	assists.SetExitTimeout(time.Hour)
+	if timeout := ExitTimeout.Get(); timeout != time.Hour {
+		t.Errorf("expect ExitTimeout %s, but got %s", time.Hour, timeout)
+	}
+}
@@ -14,10 +14,39 @@
 
 package defaults
 
-import "github.com/xgfone/go-defaults/assists"
+import (
+	"context"
+
+	"github.com/xgfone/go-defaults/assists"
+	"github.com/xgfone/go-defaults/internal/lifecycle"
+)
+
+// register registers the function f into the default lifecycle,
+// and records the caller of the wrapper calling register.
+var register = lifecycle.Register.(func(int, assists.Stage, assists.Hook, any) assists.Handle)
+
+// Register registers the function f called in the stage sorted by the hook h,
+// which is the proxy of assists.Register.
+func Register(stage assists.Stage, h assists.Hook, f func(context.Context) error) assists.Handle {
+	return register(1, stage, h, f)
+}
 
 // OnInit registers the init function f, which is the proxy of assists.OnInit.
-func OnInit(f func()) { assists.OnInit(f) }
+func OnInit(f func()) assists.Handle { return register(1, assists.StageInit, assists.Hook{}, f) }
 
 // OnInitPre registers the pre-init function f, which is the proxy of assists.OnInitPre.
-func OnInitPre(f func()) { assists.OnInitPre(f) }
+func OnInitPre(f func()) assists.Handle {
+	return register(1, assists.StageInitPre, assists.Hook{}, f)
+}
+
+// OnInitE registers the init function f returning an error,
+// which is the proxy of assists.OnInitE.
+func OnInitE(f func(context.Context) error) assists.Handle {
+	return register(1, assists.StageInit, assists.Hook{}, f)
+}
+
+// OnInitPreE registers the pre-init function f returning an error,
+// which is the proxy of assists.OnInitPreE.
+func OnInitPreE(f func(context.Context) error) assists.Handle {
+	return register(1, assists.StageInitPre, assists.Hook{}, f)
+}
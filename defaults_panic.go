@@ -16,14 +16,23 @@ package defaults
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
+	"net/http"
 
 	"github.com/xgfone/go-toolkit/runtimex"
 )
 
 var (
 	// HandlePanicFunc is used to handle the panic value returned by recover().
-	HandlePanicFunc = NewValueWithValidation(handlePanic, fActxAiface("HandlePanic"))
+	//
+	// For the default implementation, it fingerprints the panic by the stacks,
+	// logs the first occurrence with the full stacks, then aggregates
+	// the count of the same panics in the window PanicReportWindow
+	// and logs it once when the window ends. See PanicStats.
+	//
+	// If CrashReportDir is set, it also writes the crash report
+	// only when the fingerprint is seen for the first time.
+	HandlePanicFunc = newValueWithValidation("HandlePanicFunc", handlePanic, fActxAiface("HandlePanic"))
 )
 
 // HandlePanic is the proxy of HandlePanicFunc to call the funciton.
@@ -32,7 +41,14 @@ func HandlePanic(ctx context.Context, r any) {
 }
 
 func handlePanic(ctx context.Context, r any) {
-	slog.Error("wrap a panic", "panic", r, "stacks", runtimex.Stacks(4))
+	stacks := runtimex.Stacks(4)
+	log, first := recordPanic(r, stacks)
+	if log {
+		Logger(ctx).ErrorContext(ctx, "wrap a panic", "panic", r, "stacks", stacks)
+	}
+	if first {
+		writeCrashReport("panic", fmt.Sprint(r), nil, stacks)
+	}
 }
 
 // Recover is a convenient function to wrap and recover the panic if occurring,
@@ -49,3 +65,65 @@ func Recover(ctx context.Context) {
 		HandlePanic(ctx, r)
 	}
 }
+
+// PanicError is the error converted from the panic by SafeCall.
+type PanicError struct {
+	Value  any              // The panic value returned by recover().
+	Stacks []runtimex.Frame // The stacks where the panic occurs.
+}
+
+// Error implements the interface error.
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns the panic value if it is an error. Or, return nil.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// SafeCall calls the function f, and converts the panic into *PanicError
+// after handling it by HandlePanic if occurring.
+func SafeCall(ctx context.Context, f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err = &PanicError{Value: r, Stacks: runtimex.Stacks(2)}
+			HandlePanic(ctx, r)
+		}
+	}()
+	return f()
+}
+
+// SafeGo starts a new goroutine to call the function f,
+// which recovers and handles the panic by HandlePanic if occurring.
+func SafeGo(ctx context.Context, f func()) {
+	go func() {
+		defer Recover(ctx)
+		f()
+	}()
+}
+
+// RecoverHandler returns a http middleware handler, which recovers
+// the panic of the next handler, handles it by HandlePanic with
+// the request context, and responds 500.
+//
+// http.ErrAbortHandler is re-panicked to abort the response.
+func RecoverHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				HandlePanic(r.Context(), v)
+				http.Error(w, http.StatusText(http.StatusInternalServerError),
+					http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
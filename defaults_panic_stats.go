@@ -0,0 +1,163 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-toolkit/runtimex"
+)
+
+var (
+	// PanicReportWindow is the window used by the default HandlePanicFunc
+	// to aggregate the same panics, which are only logged in full once
+	// per window.
+	//
+	// Default: 1m. If 0, log every panic in full.
+	PanicReportWindow = newValue("PanicReportWindow", time.Minute)
+
+	// PanicStatsLimit is the maximum number of the panic fingerprints
+	// recorded by the default HandlePanicFunc. When exceeded, the least
+	// recently seen one is evicted.
+	//
+	// Default: 1024. If 0, no limit.
+	PanicStatsLimit = newValue("PanicStatsLimit", 1024)
+)
+
+// PanicStat is the statistics of the same panics fingerprinted by the stacks.
+type PanicStat struct {
+	Fingerprint string
+	Location    string // The frame where the panic occurs.
+	Value       string // The last panic value.
+	Count       uint64 // The total number of the panics.
+	First       time.Time
+	Last        time.Time
+}
+
+type panicstat struct {
+	PanicStat
+	window     time.Time // The start time of the current window.
+	suppressed uint64    // The number of the suppressed panics in the window.
+	timer      *time.Timer
+}
+
+var (
+	panicslock sync.Mutex
+	panicstats = make(map[string]*panicstat, 8)
+)
+
+// PanicStats returns the statistics of the panics handled
+// by the default HandlePanicFunc, sorted by Count descendingly,
+// which may be scraped as the metrics.
+func PanicStats() []PanicStat {
+	panicslock.Lock()
+	stats := make([]PanicStat, 0, len(panicstats))
+	for _, s := range panicstats {
+		stats = append(stats, s.PanicStat)
+	}
+	panicslock.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Count > stats[j].Count })
+	return stats
+}
+
+// recordPanic records the panic, and reports whether to log it in full
+// and whether the fingerprint is seen for the first time.
+func recordPanic(r any, stacks []runtimex.Frame) (log, first bool) {
+	fingerprint := fingerprintStacks(stacks)
+	window := PanicReportWindow.Get()
+	now := time.Now()
+
+	panicslock.Lock()
+	defer panicslock.Unlock()
+
+	stat, ok := panicstats[fingerprint]
+	if !ok {
+		if limit := PanicStatsLimit.Get(); limit > 0 && len(panicstats) >= limit {
+			evictPanicStat()
+		}
+
+		stat = new(panicstat)
+		stat.Fingerprint = fingerprint
+		stat.First = now
+		if len(stacks) > 0 {
+			stat.Location = stacks[0].String()
+		}
+		panicstats[fingerprint] = stat
+	}
+
+	stat.Count++
+	stat.Last = now
+	stat.Value = fmt.Sprint(r)
+
+	if window <= 0 || !ok || now.Sub(stat.window) >= window {
+		stat.window = now
+		return true, !ok
+	}
+
+	stat.suppressed++
+	if stat.timer == nil {
+		stat.timer = time.AfterFunc(stat.window.Add(window).Sub(now), func() {
+			flushPanicStat(stat)
+		})
+	}
+	return false, false
+}
+
+// evictPanicStat evicts the least recently seen panic stat,
+// which must be called with the lock.
+func evictPanicStat() {
+	var oldest *panicstat
+	for _, stat := range panicstats {
+		if oldest == nil || stat.Last.Before(oldest.Last) {
+			oldest = stat
+		}
+	}
+
+	if oldest != nil {
+		delete(panicstats, oldest.Fingerprint)
+		if oldest.timer != nil && oldest.timer.Stop() {
+			go flushPanicStat(oldest)
+		}
+	}
+}
+
+func flushPanicStat(stat *panicstat) {
+	panicslock.Lock()
+	suppressed, value := stat.suppressed, stat.Value
+	stat.suppressed, stat.timer = 0, nil
+	panicslock.Unlock()
+
+	if suppressed > 0 {
+		Logger(context.Background()).Error("the panic repeated", "fingerprint", stat.Fingerprint,
+			"location", stat.Location, "panic", value, "count", suppressed)
+	}
+}
+
+func fingerprintStacks(stacks []runtimex.Frame) string {
+	h := fnv.New64a()
+	for _, frame := range stacks {
+		_, _ = h.Write([]byte(frame.File))
+		_, _ = h.Write([]byte(frame.Func))
+		_, _ = h.Write([]byte(strconv.FormatInt(int64(frame.Line), 10)))
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
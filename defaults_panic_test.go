@@ -0,0 +1,115 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSafeCall(t *testing.T) {
+	HandlePanicFunc.Set(func(context.Context, any) {})
+	defer HandlePanicFunc.Set(handlePanic)
+
+	err := SafeCall(context.Background(), func() error { panic(io.EOF) })
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expect a PanicError, but got %v", err)
+	} else if len(perr.Stacks) == 0 {
+		t.Errorf("expect the panic stacks, but got nothing")
+	}
+
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expect the panic error '%v', but got '%v'", io.EOF, perr.Value)
+	}
+
+	if err := SafeCall(context.Background(), func() error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRecoverHandler(t *testing.T) {
+	var panicked any
+	HandlePanicFunc.Set(func(_ context.Context, r any) { panicked = r })
+	defer HandlePanicFunc.Set(handlePanic)
+
+	handler := RecoverHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("test")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expect status code %d, but got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if panicked != "test" {
+		t.Errorf("expect panic value '%v', but got '%v'", "test", panicked)
+	}
+}
+
+func resetPanicStats() {
+	panicslock.Lock()
+	defer panicslock.Unlock()
+	for fingerprint, stat := range panicstats {
+		if stat.timer != nil {
+			stat.timer.Stop()
+		}
+		delete(panicstats, fingerprint)
+	}
+}
+
+func TestPanicStats(t *testing.T) {
+	resetPanicStats()
+	for i := 0; i < 3; i++ {
+		_ = SafeCall(context.Background(), func() error { panic("stats") })
+	}
+
+	for _, stat := range PanicStats() {
+		if stat.Value == "stats" {
+			if stat.Count != 3 {
+				t.Errorf("expect panic count %d, but got %d", 3, stat.Count)
+			}
+			return
+		}
+	}
+	t.Errorf("not found the panic stat")
+}
+
+func TestPanicStatsLimit(t *testing.T) {
+	PanicStatsLimit.Set(2)
+	defer PanicStatsLimit.Set(1024)
+
+	resetPanicStats()
+	defer resetPanicStats()
+
+	_ = SafeCall(context.Background(), func() error { panic("limit1") })
+	_ = SafeCall(context.Background(), func() error { panic("limit2") })
+	_ = SafeCall(context.Background(), func() error { panic("limit3") })
+
+	stats := PanicStats()
+	if len(stats) != 2 {
+		t.Fatalf("expect %d panic stats, but got %d", 2, len(stats))
+	}
+	for _, stat := range stats {
+		if stat.Value == "limit1" {
+			t.Errorf("expect the oldest panic stat is evicted, but got it")
+		}
+	}
+}
@@ -21,19 +21,30 @@ import (
 
 var (
 	// HeaderXRequestID is used by GetRequestIDFunc to try
-	// to get the request id from the http request.
+	// to get the request id from the http request
+	// if RequestIDHeaders is empty.
 	HeaderXRequestID = "X-Request-Id"
 
 	// GetRequestIDFunc is used to get the unique request session id.
 	//
 	// For the default implementation, it only supports the types and interfaces:
 	//
-	// 	*http.Request
+	// 	*http.Request (get it from the headers by RequestIDHeaders)
 	//	interface{ RequestID() string }
 	//	interface{ GetRequestID() string }
 	//
-	// Or, return "".
-	GetRequestIDFunc = NewValueWithValidation(getRequestID, fActxAifaceR1[string]("GetRequestID"))
+	// Or, return the request id stored in the context by WithRequestID.
+	GetRequestIDFunc = newValueWithValidation("GetRequestIDFunc", getRequestID, fActxAifaceR1[string]("GetRequestID"))
+
+	// GenerateRequestIDFunc is used to generate a new unique request session id.
+	//
+	// Default: NewUUIDv4
+	//
+	// Other builtin formats are NewUUIDv7, NewULID and NewXID, for example,
+	//
+	//	GenerateRequestIDFunc.Set(NewULID)
+	GenerateRequestIDFunc = newValueWithValidation("GenerateRequestIDFunc", NewUUIDv4,
+		fR1Validation[string]("GenerateRequestID"))
 )
 
 // GetRequestID is the proxy of GetRequestIDFunc to call the function.
@@ -41,18 +52,56 @@ func GetRequestID(ctx context.Context, req any) string {
 	return GetRequestIDFunc.Get()(ctx, req)
 }
 
-func getRequestID(ctx context.Context, req any) string {
+// GenerateRequestID is the proxy of GenerateRequestIDFunc to call the function.
+func GenerateRequestID() string { return GenerateRequestIDFunc.Get()() }
+
+func getRequestID(ctx context.Context, req any) (id string) {
 	switch r := req.(type) {
 	case interface{ RequestID() string }:
-		return r.RequestID()
+		id = r.RequestID()
 
 	case interface{ GetRequestID() string }:
-		return r.GetRequestID()
+		id = r.GetRequestID()
 
 	case *http.Request:
-		return r.Header.Get(HeaderXRequestID)
+		id = extractRequestID(r.Header)
+	}
 
-	default:
-		return ""
+	if id == "" && ctx != nil {
+		id = RequestIDFromContext(ctx)
 	}
+	return
+}
+
+type requestidkey struct{}
+
+// WithRequestID returns a new context carrying the request id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestidkey{}, id)
+}
+
+// RequestIDFromContext returns the request id stored in the context
+// by WithRequestID. Or, return "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestidkey{}).(string)
+	return id
+}
+
+// RequestIDHandler returns a http middleware handler, which reuses
+// the request id got by GetRequestID or generates a new one
+// by GenerateRequestID if not exist, then stores it into the request
+// context and sets it into the response header named HeaderXRequestID.
+//
+// So the later handlers can get it by GetRequestID(r.Context(), nil).
+func RequestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := GetRequestID(ctx, r)
+		if id == "" {
+			id = GenerateRequestID()
+		}
+
+		w.Header().Set(HeaderXRequestID, id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(ctx, id)))
+	})
 }
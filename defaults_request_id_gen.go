@@ -0,0 +1,134 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// NewUUIDv4 returns a new random UUID of version 4, such as
+// "8a6e0804-2bd0-4672-b79d-d97027f9071a".
+func NewUUIDv4() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = (id[6] & 0x0f) | 0x40 // Version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant RFC 4122
+	return formatUUID(id)
+}
+
+// NewUUIDv7 returns a new time-ordered UUID of version 7, such as
+// "01929a1f-5c3b-7cc4-8b8a-3bd2d2b1e9f5".
+func NewUUIDv7() string {
+	var id [16]byte
+	_, _ = rand.Read(id[6:])
+	putUint48(id[:6], uint64(time.Now().UnixMilli()))
+	id[6] = (id[6] & 0x0f) | 0x70 // Version 7
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant RFC 4122
+	return formatUUID(id)
+}
+
+func formatUUID(id [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf[:])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new ULID, which is a 26-character string encoded
+// by the Crockford's base32, such as "01JAD1YQ2Z5V8X6GQ3WNB7K4CM".
+func NewULID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[6:])
+	putUint48(id[:6], uint64(time.Now().UnixMilli()))
+
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var buf [26]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+var (
+	xidEncoding = base32.NewEncoding("0123456789abcdefghijklmnopqrstuv").WithPadding(base32.NoPadding)
+	xidMachine  = xidMachineID()
+	xidCounter  = xidRandomCounter()
+	xidPid      = uint16(os.Getpid())
+)
+
+// NewXID returns a new globally unique id compatible with
+// https://github.com/rs/xid, which is a 20-character string,
+// such as "csa3f4ufr0j1hbd1r6c0".
+func NewXID() string {
+	var id [12]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(time.Now().Unix()))
+	copy(id[4:7], xidMachine[:])
+	binary.BigEndian.PutUint16(id[7:9], xidPid)
+
+	i := xidCounter.Add(1)
+	id[9], id[10], id[11] = byte(i>>16), byte(i>>8), byte(i)
+	return xidEncoding.EncodeToString(id[:])
+}
+
+func xidMachineID() (id [3]byte) {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		_, _ = rand.Read(id[:])
+		return
+	}
+
+	sum := md5.Sum([]byte(hostname))
+	copy(id[:], sum[:])
+	return
+}
+
+func xidRandomCounter() *atomic.Uint32 {
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+
+	counter := new(atomic.Uint32)
+	counter.Store(uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]))
+	return counter
+}
+
+func putUint48(b []byte, v uint64) {
+	_ = b[5]
+	b[0] = byte(v >> 40)
+	b[1] = byte(v >> 32)
+	b[2] = byte(v >> 24)
+	b[3] = byte(v >> 16)
+	b[4] = byte(v >> 8)
+	b[5] = byte(v)
+}
@@ -0,0 +1,229 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader represents a http header carrying the request id.
+type RequestIDHeader struct {
+	// Name is the name of the http header.
+	Name string
+
+	// Extract is used to extract the request id from the header value.
+	//
+	// If nil, use the header value as the request id.
+	Extract func(value string) (id string)
+
+	// Inject is used to inject the request id into the http header.
+	//
+	// If nil, set the header named Name to the request id.
+	Inject func(header http.Header, id string)
+}
+
+var (
+	// RequestIDHeaders is the ordered list of the http headers,
+	// from which GetRequestIDFunc tries to get the request id
+	// from the http request in turn, and into which InjectRequestID
+	// injects the request id.
+	//
+	// Default: nil, which only uses the header named HeaderXRequestID.
+	//
+	// Example:
+	//
+	//	RequestIDHeaders.Set([]RequestIDHeader{
+	//	    TraceparentRequestIDHeader,
+	//	    B3RequestIDHeader,
+	//	    B3TraceIDRequestIDHeader,
+	//	    {Name: HeaderXRequestID},
+	//	})
+	RequestIDHeaders = newValue("RequestIDHeaders", []RequestIDHeader(nil))
+
+	// TraceparentRequestIDHeader uses the trace-id of the W3C trace context
+	// header "traceparent" as the request id.
+	//
+	// When injecting, only the request id with 32 lower-case hex characters
+	// is supported, and a new random parent-id is generated.
+	TraceparentRequestIDHeader = RequestIDHeader{
+		Name:    "Traceparent",
+		Extract: extractTraceparent,
+		Inject:  injectTraceparent,
+	}
+
+	// B3RequestIDHeader uses the trace-id of the B3 single header "b3"
+	// as the request id.
+	//
+	// When injecting, only the request id with 16 or 32 lower-case hex
+	// characters is supported, and a new random span-id is generated.
+	B3RequestIDHeader = RequestIDHeader{
+		Name:    "B3",
+		Extract: extractB3,
+		Inject:  injectB3,
+	}
+
+	// B3TraceIDRequestIDHeader uses the B3 multiple header "X-B3-TraceId"
+	// as the request id.
+	//
+	// When injecting, only the request id with 16 or 32 lower-case hex
+	// characters is supported, and the header "X-B3-SpanId" is also set
+	// with a new random span-id.
+	B3TraceIDRequestIDHeader = RequestIDHeader{
+		Name:    "X-B3-TraceId",
+		Extract: extractB3TraceID,
+		Inject:  injectB3TraceID,
+	}
+)
+
+func getRequestIDHeaders() []RequestIDHeader {
+	if headers := RequestIDHeaders.Get(); len(headers) > 0 {
+		return headers
+	}
+	return []RequestIDHeader{{Name: HeaderXRequestID}}
+}
+
+func extractRequestID(header http.Header) string {
+	for _, h := range getRequestIDHeaders() {
+		value := header.Get(h.Name)
+		if value == "" {
+			continue
+		}
+
+		if h.Extract != nil {
+			value = h.Extract(value)
+		}
+
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// InjectRequestID injects the request id got by GetRequestID(ctx, nil)
+// into the http header by RequestIDHeaders, so that the outgoing request
+// carries the same request id.
+//
+// The header that has been set is ignored and kept as it is.
+func InjectRequestID(ctx context.Context, header http.Header) {
+	id := GetRequestID(ctx, nil)
+	if id == "" {
+		return
+	}
+
+	for _, h := range getRequestIDHeaders() {
+		switch {
+		case header.Get(h.Name) != "":
+		case h.Inject != nil:
+			h.Inject(header, id)
+		default:
+			header.Set(h.Name, id)
+		}
+	}
+}
+
+/// ----------------------------------------------------------------------- ///
+
+// traceparent: {version}-{trace-id}-{parent-id}-{trace-flags}
+func extractTraceparent(value string) string {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ""
+	}
+
+	if parts[0] == "00" && len(parts) != 4 {
+		return ""
+	}
+
+	if !isTraceID(parts[1], 32) || !isTraceID(parts[2], 16) {
+		return ""
+	}
+
+	return parts[1]
+}
+
+func injectTraceparent(header http.Header, id string) {
+	if isTraceID(id, 32) {
+		header.Set("Traceparent", "00-"+id+"-"+newSpanID()+"-01")
+	}
+}
+
+// b3: {trace-id}-{span-id}[-{sampling-state}[-{parent-span-id}]]
+func extractB3(value string) string {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 2 || len(parts) > 4 || !isTraceID(parts[1], 16) {
+		return ""
+	}
+	return extractB3TraceID(parts[0])
+}
+
+func injectB3(header http.Header, id string) {
+	if isB3TraceID(id) {
+		header.Set("B3", id+"-"+newSpanID())
+	}
+}
+
+func extractB3TraceID(value string) string {
+	if value = strings.TrimSpace(value); isB3TraceID(value) {
+		return value
+	}
+	return ""
+}
+
+func injectB3TraceID(header http.Header, id string) {
+	if isB3TraceID(id) {
+		header.Set("X-B3-TraceId", id)
+		if header.Get("X-B3-SpanId") == "" {
+			header.Set("X-B3-SpanId", newSpanID())
+		}
+	}
+}
+
+func isB3TraceID(s string) bool {
+	return isTraceID(s, 16) || isTraceID(s, 32)
+}
+
+// isTraceID reports whether s is a non-zero lower-case hex string with length n.
+func isTraceID(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	var nonzero bool
+	for i := 0; i < n; i++ {
+		switch c := s[i]; {
+		case c == '0':
+		case '1' <= c && c <= '9', 'a' <= c && c <= 'f':
+			nonzero = true
+		default:
+			return false
+		}
+	}
+	return nonzero
+}
+
+func newSpanID() string {
+	var id [8]byte
+	for {
+		_, _ = rand.Read(id[:])
+		if id != [8]byte{} {
+			return hex.EncodeToString(id[:])
+		}
+	}
+}
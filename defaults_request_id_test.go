@@ -0,0 +1,124 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		gen    func() string
+		format *regexp.Regexp
+	}{
+		{"UUIDv4", NewUUIDv4, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"UUIDv7", NewUUIDv7, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"ULID", NewULID, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)},
+		{"XID", NewXID, regexp.MustCompile(`^[0-9a-v]{20}$`)},
+	}
+
+	for _, test := range tests {
+		id1, id2 := test.gen(), test.gen()
+		if !test.format.MatchString(id1) {
+			t.Errorf("%s: invalid id '%s'", test.name, id1)
+		}
+		if id1 == id2 {
+			t.Errorf("%s: expect different ids, but got the same '%s'", test.name, id1)
+		}
+	}
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	var id string
+	handler := RequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = GetRequestID(r.Context(), nil)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(rec, req)
+	if id == "" {
+		t.Errorf("expect a generated request id, but got ''")
+	} else if v := rec.Header().Get(HeaderXRequestID); v != id {
+		t.Errorf("expect response request id '%s', but got '%s'", id, v)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderXRequestID, "abc")
+	handler.ServeHTTP(rec, req)
+	if id != "abc" {
+		t.Errorf("expect request id '%s', but got '%s'", "abc", id)
+	} else if v := rec.Header().Get(HeaderXRequestID); v != id {
+		t.Errorf("expect response request id '%s', but got '%s'", id, v)
+	}
+
+	if id := GetRequestID(context.Background(), nil); id != "" {
+		t.Errorf("expect no request id, but got '%s'", id)
+	}
+}
+
+func TestRequestIDHeaders(t *testing.T) {
+	RequestIDHeaders.Set([]RequestIDHeader{
+		TraceparentRequestIDHeader,
+		B3RequestIDHeader,
+		B3TraceIDRequestIDHeader,
+		{Name: HeaderXRequestID},
+	})
+	defer RequestIDHeaders.Set(nil)
+
+	const traceid = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		header string
+		value  string
+		expect string
+	}{
+		{"Traceparent", "00-" + traceid + "-00f067aa0ba902b7-01", traceid},
+		{"Traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"B3", traceid + "-e457b5a2e4d86bd1-1", traceid},
+		{"B3", "a3ce929d0e0e4736-e457b5a2e4d86bd1", "a3ce929d0e0e4736"},
+		{"B3", "0", ""},
+		{"X-B3-TraceId", traceid, traceid},
+		{"X-B3-TraceId", "xyz", ""},
+		{"X-Request-Id", "abc", "abc"},
+	}
+
+	for _, test := range tests {
+		req := &http.Request{Header: http.Header{}}
+		req.Header.Set(test.header, test.value)
+		if id := GetRequestID(context.Background(), req); id != test.expect {
+			t.Errorf("%s: expect request id '%s', but got '%s'", test.header, test.expect, id)
+		}
+	}
+
+	header := http.Header{}
+	header.Set(HeaderXRequestID, "xyz")
+	InjectRequestID(WithRequestID(context.Background(), traceid), header)
+	if v := header.Get(HeaderXRequestID); v != "xyz" {
+		t.Errorf("expect the existed request id '%s', but got '%s'", "xyz", v)
+	}
+	for _, name := range []string{"Traceparent", "B3", "X-B3-TraceId"} {
+		req := &http.Request{Header: http.Header{}}
+		req.Header.Set(name, header.Get(name))
+		if id := GetRequestID(context.Background(), req); id != traceid {
+			t.Errorf("%s: expect request id '%s', but got '%s'", name, traceid, id)
+		}
+	}
+}
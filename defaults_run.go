@@ -0,0 +1,242 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+var (
+	// ServerShutdownTimeout is the timeout used by RunServers
+	// to shut down each server, so that a hung connection
+	// does not block the exit forever.
+	//
+	// Default: 30s. If 0, no timeout.
+	ServerShutdownTimeout = newValue("ServerShutdownTimeout", time.Second*30)
+)
+
+// SignalError is returned by Run when the program exits by the exit signal.
+type SignalError struct {
+	Signal os.Signal
+}
+
+// Error implements the interface error.
+func (e *SignalError) Error() string {
+	return "exit by the signal " + e.Signal.String()
+}
+
+// ExitCode returns the exit code of the signal by SignalExitCode.
+func (e *SignalError) ExitCode() int { return SignalExitCode(e.Signal) }
+
+// Run runs the main function f of the application, and returns
+// after all the exit functions finish, which does as follow:
+//
+//  1. Call assists.RunInitContext, and return its error if failing.
+//  2. Watch the exit, reload and user signals.
+//  3. Call f by SafeCall with a context, which is cancelled when RunExit
+//     starts, the exit signal occurs or ctx is done.
+//  4. When the exit signal occurs, call assists.RunExit, which cancels
+//     the context of f and waits for f to return before calling the other
+//     exit functions in the default group "", then return a *SignalError,
+//     which is joined with the error returned by f if not nil.
+//  5. When f returns, call assists.RunExit and return the error of f.
+//
+// If the exit signal occurs again during the exit, the program is forced
+// to exit like SignalForGracefulExit.
+//
+// NOTICE: the exit functions in the other groups run concurrently
+// with the default group, so they may run before f returns.
+// So the exit functions depending on f should be in the default group.
+//
+// Run does not exit the program, so the caller should map the returned
+// error to the exit code like the example below, or use RunAndExit instead.
+//
+// Example:
+//
+//	func main() {
+//	    err := defaults.Run(context.Background(), func(ctx context.Context) error {
+//	        // TODO: run the application until ctx is done.
+//	        <-ctx.Done()
+//	        return nil
+//	    })
+//
+//	    var serr *defaults.SignalError
+//	    switch {
+//	    case errors.As(err, &serr):
+//	        os.Exit(serr.ExitCode())
+//	    case err != nil:
+//	        slog.Error("fail to run the application", "err", err)
+//	        os.Exit(1)
+//	    }
+//	}
+func Run(ctx context.Context, f func(context.Context) error) (err error) {
+	if f == nil {
+		panic("Run: the main function must not be nil")
+	}
+
+	if err = assists.RunInitContext(ctx); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Let the main function return first when exiting.
+	done := make(chan struct{})
+	handle := assists.Register(assists.StageExit, assists.Hook{Name: "defaults.Run", Priority: math.MinInt},
+		func(context.Context) error { cancel(); <-done; return nil })
+	defer handle.Cancel()
+
+	sigch := make(chan os.Signal, 2)
+	signal.Notify(sigch, ExitSignals()...)
+	defer signal.Stop(sigch)
+
+	// Wait for the signal watchers, which stop when exiting, before returning.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	wg.Add(2)
+	go func() { defer wg.Done(); SignalForReload() }()
+	go func() { defer wg.Done(); SignalForUser() }()
+
+	errch := make(chan error, 1)
+	go func() {
+		defer close(done)
+		errch <- SafeCall(ctx, func() error { return f(ctx) })
+	}()
+
+	select {
+	case err = <-errch:
+		assists.RunExit()
+
+	case sig := <-sigch:
+		exited := make(chan struct{})
+		go func() {
+			defer close(exited)
+			assists.RunExit()
+		}()
+
+		waitOrForceExit(exited, sigch)
+		if err = <-errch; err != nil {
+			err = errors.Join(&SignalError{Signal: sig}, err)
+		} else {
+			err = &SignalError{Signal: sig}
+		}
+		<-exited
+	}
+
+	return
+}
+
+// RunAndExit is the same as Run, but exits the program by Exit
+// after Run returns, with the exit code as follow:
+//
+//   - 0 if Run returns nil.
+//   - SignalExitCode of the signal if Run returns a *SignalError only.
+//   - The code of the first error implementing ExitCoder, or FatalExitCode,
+//     for other errors, which are logged.
+//
+// Example:
+//
+//	func main() {
+//	    defaults.RunAndExit(context.Background(), func(ctx context.Context) error {
+//	        // TODO: run the application until ctx is done.
+//	        <-ctx.Done()
+//	        return nil
+//	    })
+//	}
+func RunAndExit(ctx context.Context, f func(context.Context) error) {
+	Exit(runExitCode(Run(ctx, f)))
+}
+
+func runExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if serr, ok := err.(*SignalError); ok {
+		return serr.ExitCode()
+	}
+
+	code := fatalExitCode([]any{err})
+	Logger(context.Background()).Error("fail to run the application", append(fatalErrArgs(err), "code", code)...)
+	return code
+}
+
+// Server represents a server which can be run and shut down,
+// such as *http.Server.
+type Server interface {
+	ListenAndServe() error
+	Shutdown(context.Context) error
+}
+
+// RunServers is a convenient function to run the servers by Run,
+// which shuts down all the servers when the exit signal occurs
+// or any server fails.
+//
+// Each server is shut down with the timeout ServerShutdownTimeout,
+// and http.ErrServerClosed returned by ListenAndServe is ignored.
+func RunServers(ctx context.Context, servers ...Server) error {
+	return Run(ctx, func(ctx context.Context) error {
+		var wg sync.WaitGroup
+		errch := make(chan error, len(servers))
+		for _, server := range servers {
+			wg.Add(1)
+			go func(server Server) {
+				defer wg.Done()
+				if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					errch <- err
+				}
+			}(server)
+		}
+
+		var errs []error
+		select {
+		case <-ctx.Done():
+		case err := <-errch:
+			errs = append(errs, err)
+		}
+
+		for _, server := range servers {
+			errs = append(errs, shutdownServer(server))
+		}
+
+		wg.Wait()
+		close(errch)
+		for err := range errch {
+			errs = append(errs, err)
+		}
+
+		return errors.Join(errs...)
+	})
+}
+
+func shutdownServer(server Server) error {
+	ctx := context.Background()
+	if timeout := ServerShutdownTimeout.Get(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return server.Shutdown(ctx)
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+func TestRun(t *testing.T) {
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	var exited bool
+	OnExit(func() { exited = true })
+
+	errrun := errors.New("run")
+	err := Run(context.Background(), func(ctx context.Context) error {
+		if state := assists.GetState(); state != assists.StateReady {
+			t.Errorf("expect the state '%s', but got '%s'", assists.StateReady, state)
+		}
+		return errrun
+	})
+
+	if !errors.Is(err, errrun) {
+		t.Errorf("expect the error '%v', but got '%v'", errrun, err)
+	}
+	if !exited {
+		t.Errorf("expect the exit functions are called")
+	}
+	if state := assists.GetState(); state != assists.StateStopped {
+		t.Errorf("expect the state '%s', but got '%s'", assists.StateStopped, state)
+	}
+}
+
+type testServer struct {
+	listen   error
+	shutdown chan error
+	hung     bool
+}
+
+func (s *testServer) ListenAndServe() error {
+	if s.listen != nil {
+		return s.listen
+	}
+	return <-s.shutdown
+}
+
+func (s *testServer) Shutdown(ctx context.Context) error {
+	if s.hung {
+		<-ctx.Done()
+		s.shutdown <- http.ErrServerClosed
+		return ctx.Err()
+	}
+	s.shutdown <- http.ErrServerClosed
+	return nil
+}
+
+func TestRunServers(t *testing.T) {
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	ServerShutdownTimeout.Set(time.Millisecond * 10)
+	defer ServerShutdownTimeout.Set(time.Second * 30)
+
+	errlisten := errors.New("listen")
+	failed := &testServer{listen: errlisten, shutdown: make(chan error, 1)}
+	normal := &testServer{shutdown: make(chan error, 1)}
+	hung := &testServer{shutdown: make(chan error, 1), hung: true}
+
+	start := time.Now()
+	err := RunServers(context.Background(), failed, normal, hung)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expect the hung server is shut down by the timeout, but took %s", elapsed)
+	}
+
+	if !errors.Is(err, errlisten) {
+		t.Errorf("expect the error '%v', but got '%v'", errlisten, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect the shutdown timeout error, but got '%v'", err)
+	}
+}
+
+func TestRunAndExit(t *testing.T) {
+	var code int
+	defer ExitFunc.Set(ExitFunc.Swap(func(c int) { code = c }))
+	defer FatalExitCode.Set(FatalExitCode.Swap(2))
+
+	for _, test := range []struct {
+		err  error
+		code int
+	}{
+		{err: nil, code: 0},
+		{err: errors.New("run"), code: 2},
+		{err: &SignalError{Signal: os.Interrupt}, code: SignalExitCode(os.Interrupt)},
+	} {
+		assists.DefaultLifecycle.Reset()
+		code = -1
+
+		RunAndExit(context.Background(), func(context.Context) error { return test.err })
+		if code != test.code {
+			t.Errorf("%v: expect the exit code %d, but got %d", test.err, test.code, code)
+		}
+	}
+	assists.DefaultLifecycle.Reset()
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package defaults
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+func interruptSelf(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(os.Interrupt)
+	}
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func waitState(state assists.State) {
+	for assists.GetState() != state {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunBySignal(t *testing.T) {
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	err := Run(context.Background(), func(ctx context.Context) error {
+		interruptSelf(t)
+		<-ctx.Done()
+		return nil
+	})
+
+	var serr *SignalError
+	if !errors.As(err, &serr) || serr.Signal != os.Interrupt {
+		t.Errorf("expect the signal error of '%s', but got '%v'", os.Interrupt, err)
+	}
+	if state := assists.GetState(); state != assists.StateStopped {
+		t.Errorf("expect the state '%s', but got '%s'", assists.StateStopped, state)
+	}
+}
+
+func TestRunForceExit(t *testing.T) {
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	code := -1
+	release := make(chan struct{})
+	defer ForceExitFunc.Set(ForceExitFunc.Swap(func(c int) { code = c; close(release) }))
+
+	err := Run(context.Background(), func(ctx context.Context) error {
+		interruptSelf(t)
+		waitState(assists.StateDraining)
+		interruptSelf(t)
+
+		<-release // Ignore ctx to let the second signal force to exit.
+		return nil
+	})
+
+	if expect := ForceExitCode.Get(); code != expect {
+		t.Errorf("expect the force exit code %d, but got %d", expect, code)
+	}
+
+	var serr *SignalError
+	if !errors.As(err, &serr) {
+		t.Errorf("expect the signal error, but got '%v'", err)
+	}
+}
@@ -15,19 +15,272 @@
 package defaults
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+var (
+	exitsignals   = []os.Signal{os.Interrupt}
+	reloadsignals []os.Signal
+	usersignals   []os.Signal
 )
 
-var exitsignals = []os.Signal{os.Interrupt}
+var (
+	// SignalExitCodeFunc is used to get the exit code when the program
+	// exits by the exit signal.
+	//
+	// Default: 128+signo for the signal with the number, such as 130
+	// for SIGINT and 143 for SIGTERM, or 1 for others.
+	SignalExitCodeFunc = newValueWithValidation("SignalExitCodeFunc", signalExitCode,
+		fA1R1Validation[os.Signal, int]("SignalExitCode"))
+
+	// ReloadSignalsFunc is used to get the signals to let the program
+	// reload by calling the functions registered by OnReload.
+	//
+	// For default, on Unix/Linux, it contains the signal syscall.SIGHUP.
+	// On others, it is empty.
+	ReloadSignalsFunc = newValueWithValidation("ReloadSignalsFunc", reloadSignalsFunc,
+		fR1Validation[[]os.Signal]("ReloadSignals"))
+
+	// UserSignalsFunc is used to get the user-defined signals to call
+	// the functions registered by OnUserSignal.
+	//
+	// For default, on Unix/Linux, it contains the signals syscall.SIGUSR1
+	// and syscall.SIGUSR2. On others, it is empty.
+	UserSignalsFunc = newValueWithValidation("UserSignalsFunc", userSignalsFunc,
+		fR1Validation[[]os.Signal]("UserSignals"))
+
+	// ForceExitTimeout is the timeout used by SignalForGracefulExit
+	// to force the program to exit after the first exit signal occurs.
+	//
+	// Default: 0, which means only the second exit signal forces to exit.
+	ForceExitTimeout = newValue("ForceExitTimeout", time.Duration(0))
+
+	// ForceExitCode is the exit code used by SignalForGracefulExit
+	// when forcing the program to exit.
+	//
+	// Default: 3
+	ForceExitCode = newValue("ForceExitCode", 3)
+
+	// ForceExitFunc is used by SignalForGracefulExit to force the program
+	// to exit without waiting for the exit functions, which should not return.
+	//
+	// Default: os.Exit
+	ForceExitFunc = newValueWithValidation("ForceExitFunc", os.Exit, fA1Validation[int]("ForceExit"))
+)
+
+// SignalExitCode is the proxy of SignalExitCodeFunc to get the exit code
+// by the exit signal.
+func SignalExitCode(sig os.Signal) int { return SignalExitCodeFunc.Get()(sig) }
+
+// ReloadSignals is the proxy of ReloadSignalsFunc to call it to get the reload signals.
+func ReloadSignals() []os.Signal { return ReloadSignalsFunc.Get()() }
+
+// UserSignals is the proxy of UserSignalsFunc to call it to get the user signals.
+func UserSignals() []os.Signal { return UserSignalsFunc.Get()() }
+
+func reloadSignalsFunc() []os.Signal { return reloadsignals }
+func userSignalsFunc() []os.Signal   { return usersignals }
+
+func signalExitCode(sig os.Signal) int {
+	if no, ok := signalNumber(sig); ok {
+		return 128 + no
+	}
+	return 1
+}
 
 // SignalForExit watches the exit signals and calls the Exit function
-// when any exit signal occurs.
+// with the code by SignalExitCode when any exit signal occurs.
 func SignalForExit() {
 	ch := make(chan os.Signal, 1)
 	defer signal.Stop(ch)
 
 	signal.Notify(ch, ExitSignals()...)
-	<-ch
-	Exit(0)
+	Exit(SignalExitCode(<-ch))
+}
+
+// SignalForGracefulExit is the same as SignalForExit, but forces
+// the program to exit by ForceExitFunc with the code ForceExitCode when
+// the exit signal occurs again or ForceExitTimeout is exceeded
+// during the graceful exit started by the first exit signal.
+//
+// Before forcing to exit, it logs the exit functions still running.
+func SignalForGracefulExit() {
+	ch := make(chan os.Signal, 2)
+	defer signal.Stop(ch)
+
+	signal.Notify(ch, ExitSignals()...)
+	code := SignalExitCode(<-ch)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Exit(code)
+	}()
+
+	waitOrForceExit(done, ch)
+}
+
+// waitOrForceExit waits until done is closed, or forces the program
+// to exit when the exit signal occurs again or ForceExitTimeout is exceeded.
+func waitOrForceExit(done <-chan struct{}, ch <-chan os.Signal) {
+	var timeout <-chan time.Time
+	if d := ForceExitTimeout.Get(); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var reason string
+	select {
+	case <-done:
+		return
+	case sig := <-ch:
+		reason = "receive the exit signal " + sig.String() + " again"
+	case <-timeout:
+		reason = "the graceful exit timed out"
+	}
+
+	running := assists.RunningExitHooks()
+	hooks := make([]string, len(running))
+	for i, h := range running {
+		hooks[i] = fmt.Sprintf("%s@%s:%d(%s)", h.Name, h.File, h.Line, h.Duration)
+	}
+
+	code := ForceExitCode.Get()
+	Logger(context.Background()).Error("force to exit", "reason", reason, "code", code, "running", hooks)
+	ForceExitFunc.Get()(code)
+}
+
+/// ----------------------------------------------------------------------- ///
+
+// Handle is the handle of a registered function, such as the reload
+// function registered by OnReload or the health check by OnHealthCheck.
+type Handle struct {
+	cancel func()
+}
+
+// Cancel removes the registered function, which is safe to be called
+// concurrently with the registration, and more than once.
+func (h Handle) Cancel() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+}
+
+type signalfunc[F any] struct{ f F }
+
+var (
+	siglock     sync.Mutex
+	reloadfuncs []*signalfunc[func()]
+	userfuncs   []*signalfunc[func(os.Signal)]
+)
+
+// OnReload registers the reload function f, which is called
+// when calling Reload or any reload signal occurs.
+//
+// The returned handle may be used to cancel the registration.
+func OnReload(f func()) Handle {
+	if f == nil {
+		panic("OnReload: reload function must not be nil")
+	}
+	return addSignalFunc(&reloadfuncs, f)
+}
+
+// OnUserSignal registers the function f, which is called
+// when any user signal occurs.
+//
+// The returned handle may be used to cancel the registration.
+func OnUserSignal(f func(os.Signal)) Handle {
+	if f == nil {
+		panic("OnUserSignal: user signal function must not be nil")
+	}
+	return addSignalFunc(&userfuncs, f)
+}
+
+func addSignalFunc[F any](funcs *[]*signalfunc[F], f F) Handle {
+	sf := &signalfunc[F]{f: f}
+
+	siglock.Lock()
+	*funcs = append(*funcs, sf)
+	siglock.Unlock()
+
+	return Handle{cancel: func() { removeSignalFunc(funcs, sf) }}
+}
+
+func removeSignalFunc[F any](funcs *[]*signalfunc[F], sf *signalfunc[F]) {
+	siglock.Lock()
+	defer siglock.Unlock()
+
+	// Copy on write, because the functions are called without the lock.
+	for i, _sf := range *funcs {
+		if _sf == sf {
+			*funcs = append((*funcs)[:i:i], (*funcs)[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reload calls the reload functions registered by OnReload in turn,
+// which recovers and handles the panic by HandlePanic.
+func Reload() {
+	siglock.Lock()
+	funcs := reloadfuncs
+	siglock.Unlock()
+
+	for _, sf := range funcs {
+		callSignalFunc(sf.f)
+	}
+}
+
+// SignalForReload watches the reload signals and calls Reload
+// when any reload signal occurs, until the exit context is done.
+func SignalForReload() {
+	watchSignals(ReloadSignals(), func(os.Signal) { Reload() })
+}
+
+// SignalForUser watches the user signals and calls the functions
+// registered by OnUserSignal when any user signal occurs,
+// until the exit context is done.
+func SignalForUser() {
+	watchSignals(UserSignals(), func(sig os.Signal) {
+		siglock.Lock()
+		funcs := userfuncs
+		siglock.Unlock()
+
+		for _, sf := range funcs {
+			callSignalFunc(func() { sf.f(sig) })
+		}
+	})
+}
+
+func callSignalFunc(f func()) {
+	defer Recover(context.Background())
+	f()
+}
+
+func watchSignals(sigs []os.Signal, f func(os.Signal)) {
+	if len(sigs) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	defer signal.Stop(ch)
+	signal.Notify(ch, sigs...)
+
+	done := ExitContext().Done()
+	for {
+		select {
+		case <-done:
+			return
+		case sig := <-ch:
+			f(sig)
+		}
+	}
 }
@@ -16,7 +16,10 @@
 
 package defaults
 
-import "syscall"
+import (
+	"os"
+	"syscall"
+)
 
 func init() {
 	exitsignals = append(exitsignals,
@@ -26,3 +29,8 @@ func init() {
 		syscall.SIGINT,
 	)
 }
+
+func signalNumber(sig os.Signal) (int, bool) {
+	no, ok := sig.(syscall.Signal)
+	return int(no), ok
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix || windows
+
+package defaults
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+type testSignal string
+
+func (s testSignal) Signal()        {}
+func (s testSignal) String() string { return string(s) }
+
+func TestSignalExitCode(t *testing.T) {
+	for _, test := range []struct {
+		sig  os.Signal
+		code int
+	}{
+		{syscall.SIGINT, 130},
+		{os.Interrupt, 130},
+		{syscall.SIGQUIT, 131},
+		{syscall.SIGABRT, 134},
+		{syscall.SIGKILL, 137},
+		{syscall.SIGTERM, 143},
+		{testSignal("test"), 1},
+	} {
+		if code := SignalExitCode(test.sig); code != test.code {
+			t.Errorf("%s: expect exit code %d, but got %d", test.sig, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-defaults/assists"
+)
+
+func TestWaitOrForceExit(t *testing.T) {
+	ForceExitTimeout.Set(time.Second)
+	defer ForceExitTimeout.Set(0)
+
+	done := make(chan struct{})
+	time.AfterFunc(time.Millisecond*10, func() { close(done) })
+
+	// It must return without forcing to exit when done is closed in time.
+	waitOrForceExit(done, make(chan os.Signal))
+}
+
+func TestWaitOrForceExitForcibly(t *testing.T) {
+	t.Cleanup(assists.DefaultLifecycle.Reset)
+
+	buf := bytes.NewBuffer(nil)
+	log := slog.New(slog.NewTextHandler(buf, nil))
+	defer LoggerFunc.Set(LoggerFunc.Swap(func(context.Context) *slog.Logger { return log }))
+
+	var code int
+	defer ForceExitFunc.Set(ForceExitFunc.Swap(func(c int) { code = c }))
+	defer ForceExitTimeout.Set(0)
+
+	for _, reason := range []string{"again", "timed out"} {
+		buf.Reset()
+		code = -1
+		assists.DefaultLifecycle.Reset()
+
+		running := make(chan struct{})
+		release := make(chan struct{})
+		Register(assists.StageExit, assists.Hook{Name: "blocking"}, func(context.Context) error {
+			close(running)
+			<-release
+			return nil
+		})
+
+		done := make(chan struct{})
+		go func() { defer close(done); assists.RunExit() }()
+		<-running
+
+		sigch := make(chan os.Signal, 1)
+		if reason == "again" {
+			sigch <- os.Interrupt
+		} else {
+			ForceExitTimeout.Set(time.Millisecond * 10)
+		}
+
+		waitOrForceExit(done, sigch)
+		close(release)
+		<-done
+
+		if expect := ForceExitCode.Get(); code != expect {
+			t.Errorf("%s: expect the force exit code %d, but got %d", reason, expect, code)
+		}
+
+		s := buf.String()
+		if !strings.Contains(s, "force to exit") || !strings.Contains(s, reason) || !strings.Contains(s, "blocking@") {
+			t.Errorf("%s: expect to log the running exit function, but got '%s'", reason, s)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	HandlePanicFunc.Set(func(context.Context, any) {})
+	defer HandlePanicFunc.Set(handlePanic)
+
+	var calls []string
+	handles := []Handle{
+		OnReload(func() { calls = append(calls, "reload1") }),
+		OnReload(func() { panic("reload") }),
+		OnReload(func() { calls = append(calls, "reload2") }),
+	}
+	t.Cleanup(func() {
+		for _, h := range handles {
+			h.Cancel()
+		}
+	})
+
+	Reload()
+	if s := strings.Join(calls, ","); s != "reload1,reload2" {
+		t.Errorf("expect calls '%s', but got '%s'", "reload1,reload2", s)
+	}
+
+	calls = nil
+	handles[0].Cancel()
+	handles[0].Cancel()
+	Reload()
+	if s := strings.Join(calls, ","); s != "reload2" {
+		t.Errorf("expect calls '%s', but got '%s'", "reload2", s)
+	}
+}
@@ -12,16 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package assists
+//go:build unix
 
-func iter[S ~[]E, E any](s S, f func(E)) {
-	for _, e := range s {
-		f(e)
-	}
-}
+package defaults
+
+import "syscall"
 
-func reverseIter[S ~[]E, E any](s S, f func(E)) {
-	for _len := len(s) - 1; _len >= 0; _len-- {
-		f(s[_len])
-	}
+func init() {
+	reloadsignals = append(reloadsignals, syscall.SIGHUP)
+	usersignals = append(usersignals, syscall.SIGUSR1, syscall.SIGUSR2)
 }
@@ -45,7 +45,7 @@ var (
 	//	    return
 	//	})
 	//
-	StructFieldNameFunc = NewValueWithValidation(assists.StructFieldNameFuncWithTags("json"),
+	StructFieldNameFunc = newValueWithValidation("StructFieldNameFunc", assists.StructFieldNameFuncWithTags("json"),
 		fA1R2Validation[reflect.StructField, string, string]("StructFieldName"))
 )
 
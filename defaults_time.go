@@ -22,16 +22,16 @@ import (
 
 var (
 	// DEPRECATED!!! Please use timex.Format instead.
-	TimeFormat = NewValue(timex.Format)
+	TimeFormat = newValue("TimeFormat", timex.Format)
 
 	// DEPRECATED!!! Please use timex.Formats instead.
-	TimeFormats = NewValue(timex.Formats)
+	TimeFormats = newValue("TimeFormats", timex.Formats)
 
 	// DEPRECATED!!! Please use timex.Now instead.
-	TimeNowFunc = NewValue(timex.Now)
+	TimeNowFunc = newValue("TimeNowFunc", timex.Now)
 
 	// DEPRECATED!!! Please use timex.Location instead.
-	TimeLocation = NewValue(timex.Location)
+	TimeLocation = newValue("TimeLocation", timex.Location)
 )
 
 func init() {
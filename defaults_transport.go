@@ -0,0 +1,108 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderXForwardedFor is used by Transport to propagate the client ip.
+var HeaderXForwardedFor = "X-Forwarded-For"
+
+var _ http.RoundTripper = new(Transport)
+
+// Transport is a http.RoundTripper to propagate the request id
+// and the client ip, which are stored in the context of the outgoing
+// request, to the downstream services.
+//
+// The headers that have been set in the outgoing request are kept
+// as they are.
+//
+// Example:
+//
+//	client := &http.Client{Transport: &Transport{
+//	    ForwardClientIP: true,
+//	    AllowedHosts:    []string{"api.example.com", "*.svc.cluster.local"},
+//	}}
+type Transport struct {
+	// Base is the underlying http.RoundTripper to send the request.
+	//
+	// If nil, use http.DefaultTransport.
+	Base http.RoundTripper
+
+	// ForwardClientIP reports whether to propagate the client ip
+	// got by GetClientIP(ctx, nil) by the header HeaderXForwardedFor.
+	ForwardClientIP bool
+
+	// AllowedHosts is the list of the hosts to which the request id
+	// and the client ip are propagated, which supports the wildcard
+	// prefix "*.", such as "*.example.com" matching "api.example.com"
+	// but not "example.com".
+	//
+	// If empty, propagate them to all the hosts.
+	AllowedHosts []string
+}
+
+// RoundTrip implements the interface http.RoundTripper.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.allow(r.URL.Hostname()) {
+		r = t.propagate(r)
+	}
+
+	if t.Base != nil {
+		return t.Base.RoundTrip(r)
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func (t *Transport) propagate(r *http.Request) *http.Request {
+	ctx := r.Context()
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header, 2)
+	}
+
+	InjectRequestID(ctx, header)
+	if t.ForwardClientIP && header.Get(HeaderXForwardedFor) == "" {
+		if ip := GetClientIP(ctx, nil); ip.IsValid() {
+			header.Set(HeaderXForwardedFor, ip.String())
+		}
+	}
+
+	// RoundTripper must not modify the original request.
+	r = r.WithContext(ctx)
+	r.Header = header
+	return r
+}
+
+func (t *Transport) allow(host string) bool {
+	if len(t.AllowedHosts) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+	for _, pattern := range t.AllowedHosts {
+		pattern = strings.ToLower(pattern)
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTransport(t *testing.T) {
+	var header http.Header
+	transport := &Transport{
+		ForwardClientIP: true,
+		AllowedHosts:    []string{"api.example.com", "*.svc.local"},
+		Base: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			header = r.Header
+			return &http.Response{StatusCode: 200}, nil
+		}),
+	}
+
+	ctx := WithRequestID(context.Background(), "abc")
+	ctx = WithClientIP(ctx, netip.MustParseAddr("1.2.3.4"))
+
+	tests := []struct {
+		url       string
+		requestid string
+		clientip  string
+	}{
+		{"http://api.example.com/path", "abc", "1.2.3.4"},
+		{"http://user.svc.local/path", "abc", "1.2.3.4"},
+		{"http://svc.local/path", "", ""},
+		{"http://www.example.com/path", "", ""},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, test.url, nil)
+		_, _ = transport.RoundTrip(req)
+
+		if v := header.Get(HeaderXRequestID); v != test.requestid {
+			t.Errorf("%s: expect request id '%s', but got '%s'", test.url, test.requestid, v)
+		}
+		if v := header.Get(HeaderXForwardedFor); v != test.clientip {
+			t.Errorf("%s: expect client ip '%s', but got '%s'", test.url, test.clientip, v)
+		}
+		if len(req.Header) != 0 {
+			t.Errorf("%s: the original request is modified", test.url)
+		}
+	}
+}
@@ -79,6 +79,16 @@ func fA2Validation[A1, A2 any](name string) func(func(A1, A2)) error {
 	}
 }
 
+//nolint:unused
+func fA3Validation[A1, A2, A3 any](name string) func(func(A1, A2, A3)) error {
+	return func(f func(A1, A2, A3)) error {
+		if f == nil {
+			return fmt.Errorf("%s function must not be nil", name)
+		}
+		return nil
+	}
+}
+
 //nolint:unused
 func fA2R1Validation[A1, A2, R1 any](name string) func(func(A1, A2) R1) error {
 	return func(f func(A1, A2) R1) error {
@@ -20,10 +20,13 @@ var (
 	// RuleValidator is used to validate whether a value conforms with the rule.
 	//
 	// Note: in general, it is used to validate a struct field with the tag rule.
-	RuleValidator = NewValue(assists.RuleValidator(nil))
+	//
+	// Default: nil, and UseBuiltinRuleValidator may be used to set it
+	// to BuiltinRuleValidator.
+	RuleValidator = newValue("RuleValidator", assists.RuleValidator(nil))
 
 	// StructValidator is used to validate whether a struct value is valid.
-	StructValidator = NewValue(assists.StructValidator(nil))
+	StructValidator = newValue("StructValidator", assists.StructValidator(nil))
 )
 
 // ValidateStruct uses Validator to validate the struct value
@@ -37,6 +40,10 @@ func ValidateStruct(value any) (err error) {
 
 // ValidateWithRule uses RuleValidator to validate a value conforms
 // with the rule if RuleValidator is not nil.
+//
+// For BuiltinRuleValidator, the rules also apply to the ZERO value
+// unless there is the rule "omitempty", that is, 0 does not conform
+// with "min=1" but conforms with "omitempty,min=1".
 func ValidateWithRule(value any, rule string) (err error) {
 	if v := RuleValidator.Get(); v != nil {
 		err = v.Validate(value, rule)
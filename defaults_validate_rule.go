@@ -0,0 +1,374 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xgfone/go-defaults/assists"
+	"github.com/xgfone/go-toolkit/timex"
+)
+
+// BuiltinRuleValidator is the built-in implementation of the rule validator,
+// which supports the rules separated by the comma as follow:
+//
+//	required     // The value must not be ZERO.
+//	omitempty    // Skip the rest rules if the value is ZERO.
+//	min=N        // The number must be not less than N, or the length of
+//	             // the string, slice, array or map must be not less than N.
+//	max=N        // Like min, but not greater than N.
+//	len=N        // The length of the string, slice, array or map must be N.
+//	oneof=a b c  // The value must be one of the values separated by the space.
+//	regex=EXPR   // The string must match the regular expression.
+//	email        // The string must be an email address.
+//	url          // The string must be an absolute url.
+//	ip           // The string must be an IPv4 or IPv6 address.
+//	cidr         // The string must be an IPv4 or IPv6 CIDR.
+//	gt=V         // The time or number must be greater than V.
+//	lt=V         // The time or number must be less than V.
+//
+// For gt and lt on the time, V is parsed by ToTime, or is "now".
+// For the time.Duration, N and V are parsed by ToDuration.
+//
+// All the rules also apply to the ZERO value, such as 0 or "", for example,
+// 0 does not conform with "min=1". Only if there is the rule "omitempty",
+// the ZERO value skips the rules except "required", but the syntax
+// of the rules is still checked. And regex must be the last rule
+// because the regular expression may contain the comma.
+//
+// Example:
+//
+//	"required,min=1,max=10"
+//	"omitempty,min=1024"
+//	"oneof=tcp udp"
+//	"required,regex=^[a-z][a-z0-9_]*$"
+var BuiltinRuleValidator = assists.RuleValidateFunc(validateRule)
+
+// UseBuiltinRuleValidator sets RuleValidator to BuiltinRuleValidator.
+func UseBuiltinRuleValidator() { RuleValidator.Set(BuiltinRuleValidator) }
+
+func validateRule(value any, rule string) error {
+	rules, err := parseRules(rule)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	if IsZero(value) {
+		var omitempty bool
+		for _, r := range rules {
+			switch r.name {
+			case "required":
+				return errors.New("the value is required")
+			case "omitempty":
+				omitempty = true
+			}
+		}
+		if omitempty {
+			return nil
+		}
+	}
+
+	for _, r := range rules {
+		if err := r.validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type rulearg struct {
+	name string
+	arg  string
+}
+
+// parseRules splits the rules and checks their syntax.
+func parseRules(rule string) (rules []rulearg, err error) {
+	rules = splitRules(rule)
+	for _, r := range rules {
+		if err = r.check(); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+func (r rulearg) check() error {
+	switch r.name {
+	case "required", "omitempty", "email", "url", "ip", "cidr":
+		if r.arg != "" {
+			return fmt.Errorf("the validation rule '%s' must not have the argument", r.name)
+		}
+
+	case "min", "max", "gt", "lt", "oneof", "regex":
+		if strings.TrimSpace(r.arg) == "" {
+			return fmt.Errorf("the validation rule '%s' must have the argument", r.name)
+		}
+
+		if r.name == "regex" {
+			if _, err := compileRegexp(r.arg); err != nil {
+				return fmt.Errorf("invalid regex rule '%s': %w", r.arg, err)
+			}
+		}
+
+	case "len":
+		if _, err := strconv.Atoi(r.arg); err != nil {
+			return fmt.Errorf("invalid len rule '%s': %w", r.arg, err)
+		}
+
+	default:
+		return fmt.Errorf("unknown validation rule '%s'", r.name)
+	}
+	return nil
+}
+
+func splitRules(rule string) (rules []rulearg) {
+	for rule = strings.TrimSpace(rule); rule != ""; {
+		var part string
+		if strings.HasPrefix(rule, "regex=") {
+			part, rule = rule, ""
+		} else if index := strings.IndexByte(rule, ','); index < 0 {
+			part, rule = rule, ""
+		} else {
+			part, rule = rule[:index], strings.TrimSpace(rule[index+1:])
+		}
+
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		name, arg, _ := strings.Cut(part, "=")
+		rules = append(rules, rulearg{name: strings.TrimSpace(name), arg: arg})
+	}
+	return
+}
+
+func (r rulearg) validate(value any) (err error) {
+	switch r.name {
+	case "required", "omitempty":
+	case "min":
+		err = validateCompare(value, r.arg, "min", func(c int) bool { return c >= 0 })
+	case "max":
+		err = validateCompare(value, r.arg, "max", func(c int) bool { return c <= 0 })
+	case "gt":
+		err = validateCompare(value, r.arg, "gt", func(c int) bool { return c > 0 })
+	case "lt":
+		err = validateCompare(value, r.arg, "lt", func(c int) bool { return c < 0 })
+	case "len":
+		err = validateLen(value, r.arg)
+	case "oneof":
+		err = validateOneOf(value, r.arg)
+	case "regex":
+		err = validateRegexp(value, r.arg)
+	case "email":
+		err = validateString(value, "email", func(s string) bool {
+			addr, err := mail.ParseAddress(s)
+			return err == nil && addr.Address == s
+		})
+	case "url":
+		err = validateString(value, "url", func(s string) bool {
+			u, err := url.ParseRequestURI(s)
+			return err == nil && u.Scheme != "" && u.Host != ""
+		})
+	case "ip":
+		err = validateString(value, "ip", func(s string) bool {
+			_, err := netip.ParseAddr(s)
+			return err == nil
+		})
+	case "cidr":
+		err = validateString(value, "cidr", func(s string) bool {
+			_, err := netip.ParsePrefix(s)
+			return err == nil
+		})
+	default:
+		err = fmt.Errorf("unknown validation rule '%s'", r.name)
+	}
+	return
+}
+
+func validateString(value any, name string, validate func(string) bool) error {
+	s, err := ToString(value)
+	if err != nil {
+		return err
+	}
+	if !validate(s) {
+		return fmt.Errorf("'%s' is not a valid %s", s, name)
+	}
+	return nil
+}
+
+func validateOneOf(value any, arg string) error {
+	s, err := ToString(value)
+	if err != nil {
+		return err
+	}
+
+	values := strings.Fields(arg)
+	for _, v := range values {
+		if v == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not one of %v", s, values)
+}
+
+var regexps sync.Map
+
+func validateRegexp(value any, expr string) error {
+	s, err := ToString(value)
+	if err != nil {
+		return err
+	}
+
+	re, err := compileRegexp(expr)
+	if err != nil {
+		return fmt.Errorf("invalid regex rule '%s': %w", expr, err)
+	}
+
+	if !re.MatchString(s) {
+		return fmt.Errorf("'%s' does not match the regex '%s'", s, expr)
+	}
+	return nil
+}
+
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if v, ok := regexps.Load(expr); ok {
+		return v.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err == nil {
+		regexps.Store(expr, re)
+	}
+	return re, err
+}
+
+func validateLen(value any, arg string) error {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("invalid len rule '%s': %w", arg, err)
+	}
+
+	length, ok := lengthOf(value)
+	if !ok {
+		return fmt.Errorf("unsupport the len rule for %T", value)
+	}
+
+	if length != n {
+		return fmt.Errorf("the length %d is not equal to %d", length, n)
+	}
+	return nil
+}
+
+// validateCompare compares the value with the argument, and checks
+// the compared result, which is -1, 0 or 1, by check.
+func validateCompare(value any, arg, name string, check func(int) bool) error {
+	c, err := compareRule(value, arg, name)
+	if err != nil {
+		return err
+	}
+
+	if !check(c) {
+		return fmt.Errorf("the value %v does not conform with the rule %s=%s", value, name, arg)
+	}
+	return nil
+}
+
+func compareRule(value any, arg, name string) (int, error) {
+	switch v := value.(type) {
+	case time.Time, *time.Time:
+		t, err := ToTime(v)
+		if err != nil {
+			return 0, err
+		}
+
+		var at time.Time
+		if arg == "now" {
+			at = timex.Now()
+		} else if at, err = ToTime(arg); err != nil {
+			return 0, fmt.Errorf("invalid %s rule '%s': %w", name, arg, err)
+		}
+		return t.Compare(at), nil
+
+	case time.Duration:
+		d, err := ToDuration(arg)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s rule '%s': %w", name, arg, err)
+		}
+		return compareNumber(v, d), nil
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, err := ToFloat64(reflect.Indirect(reflect.ValueOf(value)).Interface())
+		if err != nil {
+			return 0, err
+		}
+
+		af, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s rule '%s': %w", name, arg, err)
+		}
+		return compareNumber(f, af), nil
+	}
+
+	if name == "min" || name == "max" {
+		if length, ok := lengthOf(value); ok {
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s rule '%s': %w", name, arg, err)
+			}
+			return compareNumber(length, n), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupport the %s rule for %T", name, value)
+}
+
+func compareNumber[T int | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// lengthOf returns the length of the string, slice, array or map.
+func lengthOf(value any) (int, bool) {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuiltinRuleValidator(t *testing.T) {
+	UseBuiltinRuleValidator()
+	defer RuleValidator.Set(nil)
+
+	now := time.Now()
+	tests := []struct {
+		value any
+		rule  string
+		valid bool
+	}{
+		{"", "", true},
+		{"", "min=1", false},
+		{"", "omitempty,min=1", true},
+		{"", "len=3", false},
+		{"", "email", false},
+		{"", "omitempty,email", true},
+		{"", "omitempty,required", false},
+		{"", "required", false},
+		{0, "required,min=1", false},
+		{5, "required,min=1,max=10", true},
+		{11, "min=1,max=10", false},
+		{2.5, "gt=2,lt=3", true},
+		{"abc", "len=3", true},
+		{"abc", "max=2", false},
+		{[]int{1, 2}, "min=2", true},
+		{map[string]int{"a": 1}, "len=2", false},
+		{time.Second, "min=1s,max=1m", true},
+		{time.Millisecond, "min=1s", false},
+		{"tcp", "oneof=tcp udp", true},
+		{"http", "oneof=tcp udp", false},
+		{8080, "oneof=80 8080", true},
+		{"abc_1", "required,regex=^[a-z][a-z0-9_,]*$", true},
+		{"1abc", "regex=^[a-z][a-z0-9_,]*$", false},
+		{"user@example.com", "email", true},
+		{"user", "email", false},
+		{"http://example.com/path", "url", true},
+		{"/path", "url", false},
+		{"127.0.0.1", "ip", true},
+		{"::1", "ip", true},
+		{"127.0.0.256", "ip", false},
+		{"10.0.0.0/8", "cidr", true},
+		{"10.0.0.0", "cidr", false},
+		{now.Add(-time.Hour), "lt=now", true},
+		{now.Add(-time.Hour), "gt=now", false},
+		{now, "gt=2000-01-01T00:00:00Z", true},
+		{"abc", "unknown", false},
+		{"", "unknown", false},
+		{0, "min", false},
+		{0, "min=1", false},
+		{0, "omitempty,min=1", true},
+		{80, "omitempty,min=1024", false},
+		{"", "omitempty=a", false},
+		{"", "len=a", false},
+		{"", "regex=(", false},
+		{"", "email=a", false},
+	}
+
+	for i, test := range tests {
+		err := ValidateWithRule(test.value, test.rule)
+		if test.valid && err != nil {
+			t.Errorf("%d: expect valid for rule '%s', but got an error: %v", i, test.rule, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%d: expect invalid for rule '%s', but got nil", i, test.rule)
+		}
+	}
+}
@@ -15,22 +15,27 @@
 package defaults
 
 import (
+	"fmt"
 	"log/slog"
 
 	"github.com/xgfone/go-defaults/assists"
-	"github.com/xgfone/go-toolkit/runtimex"
 )
 
-func loginfo(msg string, kvs ...any) {
-	if assists.DEBUG {
-		slog.Info(msg, kvs...)
+func logset(value any) {
+	if assists.Tracing(assists.TraceValueSet) {
+		assists.Trace(assists.TraceValueSet, 2, "set the default", slog.String("type", fmt.Sprintf("%T", value)))
 	}
 }
 
-func logset() {
-	loginfo("set the default", "caller", runtimex.Caller(0))
+func logswap(value any) {
+	if assists.Tracing(assists.TraceValueSet) {
+		assists.Trace(assists.TraceValueSet, 2, "swap the default", slog.String("type", fmt.Sprintf("%T", value)))
+	}
 }
 
-func logswap() {
-	loginfo("swap the default", "caller", runtimex.Caller(0))
+func tracecast(name string, input any, err error) {
+	if err != nil && assists.Tracing(assists.TraceCastFailure) {
+		assists.Trace(assists.TraceCastFailure, 2, "fail to cast the value", slog.String("func", name),
+			slog.String("input", fmt.Sprintf("%T", input)), slog.Any("err", err))
+	}
 }
@@ -18,6 +18,7 @@ package defaults
 type Value[T any] struct {
 	verify func(T) error
 	update func(T)
+	load   func() T
 	value  T
 }
 
@@ -33,8 +34,36 @@ func NewValueWithValidation[T any](initial T, validate func(T) error) *Value[T]
 	return &Value[T]{verify: validate, value: initial}
 }
 
+// newValue is the same as NewValue, but registers the default value
+// named name to be dumped by Diagnose.
+func newValue[T any](name string, initial T) *Value[T] {
+	return newValueWithValidation(name, initial, nil)
+}
+
+// newValueWithValidation is the same as NewValueWithValidation,
+// but registers the default value named name to be dumped by Diagnose.
+func newValueWithValidation[T any](name string, initial T, validate func(T) error) *Value[T] {
+	v := NewValueWithValidation(initial, validate)
+	diagnose(name, v)
+	return v
+}
+
+// newProxyValue returns a new Value named name, which proxies the value
+// stored by load and store, such as the one in the package assists,
+// so that there is only one source of truth.
+func newProxyValue[T any](name string, load func() T, store func(T), validate func(T) error) *Value[T] {
+	v := newValueWithValidation(name, load(), validate)
+	v.load, v.update = load, store
+	return v
+}
+
 // Get returns the inner value.
-func (v *Value[T]) Get() T { return v.value }
+func (v *Value[T]) Get() T {
+	if v.load != nil {
+		return v.load()
+	}
+	return v.value
+}
 
 // Set sets the value to new.
 //
@@ -44,7 +73,7 @@ func (v *Value[T]) Set(new T) {
 		panic(err)
 	}
 	v.value = new
-	logset()
+	logset(v.value)
 	if v.update != nil {
 		v.update(v.value)
 	}
@@ -57,9 +86,12 @@ func (v *Value[T]) Swap(new T) (old T) {
 	if err := v.Validate(new); err != nil {
 		panic(err)
 	}
-	old = v.value
+	old = v.Get()
 	v.value = new
-	logswap()
+	logswap(v.value)
+	if v.update != nil {
+		v.update(v.value)
+	}
 	return
 }
 
@@ -0,0 +1,28 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lifecycle is used to share the registration of the lifecycle
+// functions between the package assists and the package defaults.
+package lifecycle
+
+// Register is set by the package assists to register a function
+// into the default lifecycle, whose type is
+//
+//	func(skip int, stage assists.Stage, h assists.Hook, f any) assists.Handle
+//
+// skip is the number of the extra stack frames to skip to get the caller,
+// so that the wrappers in the package defaults record their callers.
+// And f is one of func(), func(context.Context), func(context.Context) error
+// and assists.Flusher.
+var Register any